@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+
+	"promptgo/internal/admin"
+)
+
+const adminUsage = `Usage:
+  ssh -t -p PORT HOST admin                    Open the operator dashboard
+  ssh -p PORT HOST admin sessions              List connected sessions
+  ssh -p PORT HOST admin spend                 Show token spend per user
+  ssh -p PORT HOST admin feedback              Show prompt ratings and gate answers per system prompt version
+  ssh -p PORT HOST admin kick ID               Disconnect a session
+  ssh -p PORT HOST admin reset-quota USER      Reset a user's token quota
+  ssh -p PORT HOST admin reload                Reload the config file for new sessions
+  ssh -p PORT HOST admin broadcast MESSAGE     Show a notice in every session; no message clears it
+  ssh -p PORT HOST admin maintenance IN [MSG]  Warn every session of maintenance in IN (e.g. 10m), with a countdown
+`
+
+// isAdmin reports whether the session's key is listed as an admin key
+func isAdmin(s ssh.Session) bool {
+	return s.PublicKey() != nil && slices.Contains(current.Load().admins, sessionUser(s))
+}
+
+// adminServer returns what the dashboard and admin commands act on
+func adminServer() admin.Server {
+	return admin.Server{Sessions: sessions, Usage: usage, Health: health, Feedback: feedbackStore, Bus: bus, Reload: reloadConfig}
+}
+
+// handleAdminCommand runs a non-interactive admin command
+func handleAdminCommand(s ssh.Session, args []string) {
+	srv := adminServer()
+	log.Printf("Admin command from %s: %s", sessionUser(s), strings.Join(args, " "))
+
+	switch args[0] {
+	case "sessions":
+		for _, info := range srv.Sessions.List() {
+			wish.Printf(s, "%d\t%s\t%s\t%s\t%s\t%s\n", info.ID, info.Name, info.User, info.State, info.Remote, time.Since(info.Started).Round(time.Second))
+		}
+
+	case "spend":
+		for _, sp := range srv.Usage.List() {
+			wish.Printf(s, "%s\t%d calls\t%d in\t%d out\t$%.4f\n", sp.User, sp.Calls, sp.InputTokens, sp.OutputTokens, sp.Cost)
+		}
+
+	case "feedback":
+		writeFeedback(s, srv.Feedback.Summaries())
+
+	case "kick":
+		if len(args) != 2 {
+			wish.Fatalf(s, "Usage: admin kick ID\n")
+			return
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			wish.Fatalf(s, "Error: invalid session ID %q\n", args[1])
+			return
+		}
+		if err := srv.Sessions.Kick(id); err != nil {
+			wish.Fatalln(s, "Error:", err)
+			return
+		}
+		wish.Println(s, "Kicked session", id)
+
+	case "reset-quota":
+		if len(args) != 2 {
+			wish.Fatalf(s, "Usage: admin reset-quota USER\n")
+			return
+		}
+		srv.Usage.Reset(args[1])
+		wish.Println(s, "Reset quota for", args[1])
+
+	case "reload":
+		if err := srv.Reload(); err != nil {
+			wish.Fatalln(s, "Error:", err)
+			return
+		}
+		wish.Println(s, "Config reloaded; new sessions use it")
+
+	case "broadcast":
+		n := srv.Broadcast(strings.Join(args[1:], " "))
+		wish.Println(s, fmt.Sprintf("Sent to %d session(s)", n))
+
+	case "maintenance":
+		if len(args) < 2 {
+			wish.Fatalf(s, "Usage: admin maintenance IN [MESSAGE]\n")
+			return
+		}
+		d, err := time.ParseDuration(args[1])
+		if err != nil || d < 0 {
+			wish.Fatalf(s, "Error: invalid duration %q, use e.g. 10m\n", args[1])
+			return
+		}
+		n := srv.Maintenance(d, strings.Join(args[2:], " "))
+		wish.Println(s, fmt.Sprintf("Warned %d session(s) of maintenance in %s", n, d))
+
+	default:
+		wish.Fatalf(s, "Unknown admin command %q\n\n%s", args[0], adminUsage)
+	}
+}
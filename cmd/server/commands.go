@@ -0,0 +1,226 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	gossh "golang.org/x/crypto/ssh"
+
+	"promptgo/internal/export"
+	"promptgo/internal/project"
+)
+
+// projects and changes hold context uploaded over the exec channel, and exports the document each
+// user last saved in a session for download, all keyed by user
+var (
+	projects = project.NewStore[*project.Context]()
+	changes  = project.NewStore[*project.Changes]()
+	exports  = project.NewStore[*export.Document]()
+)
+
+// maxStdinSize bounds input piped to exec commands
+const maxStdinSize = 10 << 20
+
+const commandUsage = `Usage:
+  ssh -p PORT HOST                                      Start the interactive TUI
+  tar czf - -C your/repo . | ssh -p PORT HOST context   Attach project context
+  ssh -p PORT HOST context clear                        Remove attached project context
+  go test ./... 2>&1 | ssh -p PORT HOST changes         Attach test output, a diff or a git log
+  ssh -p PORT HOST changes clear                        Remove attached changes
+  ssh -p PORT HOST export -format markdown > out.md     Download the prompt you last saved in a session
+      -format text|markdown|json|html
+  ssh -p PORT HOST rules -format cursor < prompt.md     Convert a prompt to an agent rule file
+      -format agents|claude|cursor|copilot  -title TITLE  -full (keep the whole prompt)
+      -saved (use the prompt you last saved in a session instead of stdin)
+  ssh -p PORT HOST show ID                              Print a prompt shared with you
+  ssh -p PORT HOST shares                               List the prompts you've shared
+  ssh -p PORT HOST revoke ID                            Stop sharing a prompt
+  ssh -t -p PORT HOST join CODE                         Join a teammate's pairing session
+  ssh -t -p PORT HOST admin                             Operator dashboard and commands (admin keys only)
+`
+
+// commandMiddleware handles non-interactive exec commands, passing interactive sessions through
+func commandMiddleware(next ssh.Handler) ssh.Handler {
+	return func(s ssh.Session) {
+		args := s.Command()
+		if len(args) == 0 {
+			next(s)
+			return
+		}
+
+		switch args[0] {
+		case "context":
+			handleContextCommand(s, args[1:])
+		case "changes":
+			handleChangesCommand(s, args[1:])
+		case "export":
+			handleExportCommand(s, args[1:])
+		case "rules":
+			handleRulesCommand(s, args[1:])
+		case "show":
+			handleShowCommand(s, args[1:])
+		case "shares":
+			handleSharesCommand(s)
+		case "revoke":
+			handleRevokeCommand(s, args[1:])
+		case "join":
+			handleJoinCommand(s, args[1:], next)
+		case "admin":
+			if !isAdmin(s) {
+				wish.Fatalln(s, "Error: admin commands need an admin key")
+				return
+			}
+			if len(args) == 1 {
+				// The dashboard runs as a Bubble Tea program, which needs a terminal
+				if _, _, ok := s.Pty(); !ok {
+					wish.Fatalln(s, "Error: the admin dashboard needs a terminal; connect with ssh -t -p PORT HOST admin")
+					return
+				}
+				next(s)
+				return
+			}
+			handleAdminCommand(s, args[1:])
+		case "help":
+			wish.Print(s, commandUsage)
+		default:
+			wish.Fatalf(s, "Unknown command %q\n\n%s", args[0], commandUsage)
+		}
+	}
+}
+
+// handleContextCommand reads a project tarball from stdin and attaches it to the user's sessions
+func handleContextCommand(s ssh.Session, args []string) {
+	user := sessionUser(s)
+
+	if len(args) > 0 && args[0] == "clear" {
+		projects.Clear(user)
+		wish.Println(s, "Project context cleared")
+		return
+	}
+
+	pc, err := project.FromTarball(s.User(), s)
+	if err != nil {
+		wish.Fatalln(s, "Error:", err)
+		return
+	}
+	projects.Set(user, pc)
+
+	log.Printf("Project context uploaded by %s: %s", s.RemoteAddr(), pc.Label())
+	wish.Printf(s, "Attached project context: %s\n\n%s\n", pc.Label(), pc.Summary(project.SummaryLimit))
+}
+
+// handleChangesCommand reads a diff, git log or go test output from stdin and attaches it to the user's sessions
+func handleChangesCommand(s ssh.Session, args []string) {
+	user := sessionUser(s)
+
+	if len(args) > 0 && args[0] == "clear" {
+		changes.Clear(user)
+		wish.Println(s, "Changes cleared")
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(s, maxStdinSize))
+	if err != nil {
+		wish.Fatalln(s, "Error:", err)
+		return
+	}
+	c := project.ParseChanges(string(data))
+	if c.Empty() {
+		wish.Fatalln(s, "Error: no diff, commits or test failures found in input")
+		return
+	}
+	changes.Set(user, c)
+
+	log.Printf("Changes uploaded by %s: %s", s.RemoteAddr(), c.Label())
+	wish.Printf(s, "Attached changes: %s\n\n%s\n", c.Label(), c.Summary(project.ChangesLimit))
+}
+
+// handleExportCommand writes the document the user last saved in a session to stdout
+func handleExportCommand(s ssh.Session, args []string) {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(s.Stderr())
+	format := fs.String("format", export.Exporters[0].Name(), "File format: text, markdown, json or html")
+	if err := fs.Parse(args); err != nil {
+		wish.Fatalln(s, "Error:", err)
+		return
+	}
+
+	e, ok := export.ExporterByName(*format)
+	if !ok {
+		wish.Fatalf(s, "Unknown format %q\n\n%s", *format, commandUsage)
+		return
+	}
+	doc := exports.Get(sessionUser(s))
+	if doc == nil {
+		wish.Fatalln(s, "Error: nothing to export; save a prompt with [s] in a session first")
+		return
+	}
+
+	data, err := export.Bytes(*doc, e)
+	if err != nil {
+		wish.Fatalln(s, "Error:", err)
+		return
+	}
+	_, _ = s.Write(data)
+}
+
+// handleRulesCommand converts a prompt read from stdin, or the one last saved in a session, into an
+// agent rule file written to stdout
+func handleRulesCommand(s ssh.Session, args []string) {
+	fs := flag.NewFlagSet("rules", flag.ContinueOnError)
+	fs.SetOutput(s.Stderr())
+	format := fs.String("format", "agents", "Rule file format: agents, claude, cursor or copilot")
+	title := fs.String("title", "", "Section heading or Cursor rule description")
+	full := fs.Bool("full", false, "Keep the whole prompt instead of its conventions sections")
+	saved := fs.Bool("saved", false, "Use the prompt last saved in a session instead of stdin")
+	if err := fs.Parse(args); err != nil {
+		wish.Fatalln(s, "Error:", err)
+		return
+	}
+
+	f, ok := export.AgentFileByID(*format)
+	if !ok {
+		wish.Fatalf(s, "Unknown format %q\n\n%s", *format, commandUsage)
+		return
+	}
+
+	var body, task string
+	if *saved {
+		doc := exports.Get(sessionUser(s))
+		if doc == nil {
+			wish.Fatalln(s, "Error: nothing saved; choose an agent rule file with [a] in a session first")
+			return
+		}
+		body, task = doc.Prompt, doc.Task
+	} else {
+		data, err := io.ReadAll(io.LimitReader(s, maxStdinSize))
+		if err != nil {
+			wish.Fatalln(s, "Error:", err)
+			return
+		}
+		body = string(data)
+	}
+	if !*full {
+		body = export.Conventions(body)
+	}
+	if *title == "" {
+		*title = export.DefaultTitle(task)
+	}
+
+	// The path goes to stderr so stdout can be redirected straight into the file
+	wish.Errorf(s, "%s\n", f.Path(*title))
+	wish.Print(s, f.Render(*title, body))
+}
+
+// sessionUser identifies the user behind a session by their public key. Every session authenticates
+// with one; the username fallback is chosen by the client, so it never matches a workspace or admin.
+func sessionUser(s ssh.Session) string {
+	if key := s.PublicKey(); key != nil {
+		return gossh.FingerprintSHA256(key)
+	}
+	return "user:" + strings.ToLower(s.User())
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+
+	"promptgo/internal/ai"
+	"promptgo/internal/config"
+	"promptgo/internal/enhancer"
+	"promptgo/internal/eval"
+)
+
+// fakeModel is reported for runs against the fake provider; it has no pricing so cost stays zero
+const fakeModel = "fake"
+
+// runEval implements `promptgo eval`, returning the process exit code
+func runEval(args []string) int {
+	flags := flag.NewFlagSet("eval", flag.ExitOnError)
+	dataset := flags.String("dataset", "eval/golden.jsonl", "JSONL dataset of tasks and assertions")
+	provider := flags.String("provider", "anthropic", "Model provider: anthropic, or fake for deterministic runs")
+	baseline := flags.String("baseline", "eval/baseline.json", "Saved report to compare against")
+	updateBaseline := flags.Bool("update-baseline", false, "Save this run as the new baseline")
+	reportPath := flags.String("report", "eval_report.md", "Where to write the markdown report")
+	flags.Parse(args)
+
+	cases, err := eval.LoadCases(*dataset)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return 1
+	}
+
+	var client *ai.Client
+	switch *provider {
+	case "fake":
+		client = ai.NewClientWithProvider(ai.FakeProvider{}, fakeModel)
+	case "anthropic":
+		cfg, err := config.Load()
+		if err != nil {
+			log.Printf("Failed to load config: %v", err)
+			return 1
+		}
+		if cfg.Anthropic.APIKey == "" {
+			log.Printf("No Anthropic API key configured; use -provider fake for offline runs")
+			return 1
+		}
+		client = ai.NewClient(cfg.Anthropic.APIKey, cfg.Anthropic.Model)
+		client.SetMaxTokens(cfg.Anthropic.MaxTokens)
+		client.SetRouting(routing(cfg.Anthropic.Routing))
+	default:
+		log.Printf("Unknown provider %q", *provider)
+		return 1
+	}
+
+	report := eval.Run(context.Background(), enhancer.NewEnhancerWithClient(client), client.Model(), cases)
+
+	var cmp *eval.Comparison
+	base, err := eval.LoadReport(*baseline)
+	switch {
+	case err == nil:
+		c := eval.Compare(base, report)
+		cmp = &c
+	case !errors.Is(err, fs.ErrNotExist):
+		log.Printf("Ignoring baseline: %v", err)
+	}
+
+	f, err := os.Create(*reportPath)
+	if err != nil {
+		log.Printf("Failed to create report: %v", err)
+		return 1
+	}
+	defer f.Close()
+	if err := eval.WriteMarkdown(f, report, cmp); err != nil {
+		log.Printf("Failed to write report: %v", err)
+		return 1
+	}
+
+	s := report.Summary
+	fmt.Printf("%d cases: %d passed, %d errors, accuracy %.1f%%, checks %.1f%%, cost $%.4f\n",
+		s.Cases, s.Passed, s.Errors, s.Accuracy*100, s.CheckPassRate*100, s.TotalCost)
+	fmt.Printf("Report written to %s\n", *reportPath)
+
+	if *updateBaseline {
+		if err := report.Save(*baseline); err != nil {
+			log.Printf("Failed to save baseline: %v", err)
+			return 1
+		}
+		fmt.Printf("Baseline saved to %s\n", *baseline)
+		return 0
+	}
+	if cmp != nil && cmp.Regressed() {
+		fmt.Printf("Regressed against baseline: %v\n", cmp.Regressions)
+		return 1
+	}
+	return 0
+}
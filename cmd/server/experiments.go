@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"promptgo/internal/ai"
+	"promptgo/internal/config"
+	"promptgo/internal/experiment"
+)
+
+// experiments converts the experiment config, warning about unknown prompt versions
+func experiments(cfgs []config.ExperimentConfig) []experiment.Experiment {
+	var out []experiment.Experiment
+	for _, ec := range cfgs {
+		e := experiment.Experiment{ID: ec.ID}
+		total := 0
+		for _, vc := range ec.Variants {
+			checkPromptVersion(ec.ID, ai.PromptAnalyzer, vc.Analyzer)
+			checkPromptVersion(ec.ID, ai.PromptGenerator, vc.Generator)
+			total += vc.Percent
+			e.Variants = append(e.Variants, experiment.Variant{
+				Name:             vc.Name,
+				Percent:          vc.Percent,
+				AnalyzerVersion:  vc.Analyzer,
+				GeneratorVersion: vc.Generator,
+			})
+		}
+		if total > 100 {
+			log.Printf("Experiment %s: variants add up to %d%%, later variants get fewer users", ec.ID, total)
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// checkPromptVersion warns when a variant names a version that would fall back to the default
+func checkPromptVersion(experimentID, kind, id string) {
+	if id == "" {
+		return
+	}
+	if got, _ := ai.SystemPrompt(kind, id); got != id {
+		log.Printf("Experiment %s: unknown %s prompt %q, using %s", experimentID, kind, id, got)
+	}
+}
+
+// runExperiments implements `promptgo experiments`, printing outcome rates per variant
+func runExperiments() int {
+	path, err := config.ExperimentLogPath()
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return 1
+	}
+	rec, err := experiment.NewRecorder(path)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return 1
+	}
+
+	tallies := rec.Tallies()
+	if len(tallies) == 0 {
+		fmt.Println("No experiment outcomes recorded yet")
+		return 0
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "EXPERIMENT\tVARIANT\tGENERATED\tCOPY\tSAVE\tREFINE\tUP\tDOWN")
+	for _, t := range tallies {
+		fmt.Fprintf(w, "%s\t%s\t%d", t.Experiment, t.Variant, t.Events[experiment.EventGenerated])
+		for _, e := range experiment.Events[1:] {
+			fmt.Fprintf(w, "\t%d (%.0f%%)", t.Events[e], 100*t.Rate(e))
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+	return 0
+}
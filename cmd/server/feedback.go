@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"promptgo/internal/config"
+	"promptgo/internal/feedback"
+)
+
+// openFeedback opens the feedback log, falling back to memory if it can't be read
+func openFeedback() *feedback.Store {
+	path, err := config.FeedbackLogPath()
+	if err == nil {
+		var store *feedback.Store
+		if store, err = feedback.NewStore(path); err == nil {
+			return store
+		}
+	}
+	log.Printf("Feedback won't be saved across restarts: %v", err)
+	store, _ := feedback.NewStore("")
+	return store
+}
+
+// recordFeedback returns a function that stores a user's feedback entries
+func recordFeedback(user string) func(feedback.Entry) {
+	return func(e feedback.Entry) {
+		e.User = user
+		if err := feedbackStore.Add(e); err != nil {
+			log.Printf("Failed to save feedback: %v", err)
+		}
+	}
+}
+
+// runFeedback implements `promptgo feedback`, printing ratings per system prompt version
+func runFeedback() int {
+	path, err := config.FeedbackLogPath()
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return 1
+	}
+	store, err := feedback.NewStore(path)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return 1
+	}
+
+	writeFeedback(os.Stdout, store.Summaries())
+	return 0
+}
+
+// writeFeedback prints feedback summaries as a table
+func writeFeedback(out io.Writer, summaries []feedback.Summary) {
+	if len(summaries) == 0 {
+		fmt.Fprintln(out, "No feedback recorded yet")
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROMPT VERSION\tUP\tDOWN\tAPPROVAL\tCOMMENTS\tGATE KEPT")
+	for _, s := range summaries {
+		version := s.PromptVersion
+		if version == "" {
+			version = "(offline)"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.0f%%\t%d\t%d/%d\n", version, s.Up, s.Down, 100*s.Approval(), s.Comments, s.GateRespected, s.GateAsked)
+	}
+	w.Flush()
+}
@@ -3,10 +3,14 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"os/user"
 	"path/filepath"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -15,17 +19,114 @@ import (
 	"github.com/charmbracelet/wish"
 	"github.com/charmbracelet/wish/bubbletea"
 	"github.com/charmbracelet/wish/logging"
+	"github.com/muesli/termenv"
 
+	"promptgo/internal/admin"
+	"promptgo/internal/ai"
+	"promptgo/internal/config"
+	"promptgo/internal/enhancer"
+	"promptgo/internal/experiment"
+	"promptgo/internal/export"
+	"promptgo/internal/feedback"
+	"promptgo/internal/monitor"
+	"promptgo/internal/project"
+	"promptgo/internal/secret"
 	"promptgo/internal/tui"
 )
 
+// settings are the parts of the config given to new sessions; operators can reload them
+type settings struct {
+	enh        *enhancer.Enhancer // nil when no API key is configured
+	secretGen  *secret.Generator
+	privacy    config.PrivacyConfig
+	running    []experiment.Experiment // System prompt experiments
+	workspaces []tui.Workspace         // Every workspace, each with its own enhancer when it overrides model or budget
+	admins     []string                // Key fingerprints allowed to run admin commands
+}
+
+// current holds the settings new sessions start with
+var current atomic.Pointer[settings]
+
+// outcomes records experiment outcomes; nil when it couldn't be opened
+var outcomes *experiment.Recorder
+
+// shareBaseURL is where link shares are served over HTTP
+var shareBaseURL string
+
+// feedbackStore holds ratings and comments on generated prompts from all sessions
+var feedbackStore *feedback.Store
+
+// sessions, usage and health are what the admin dashboard shows; bus reaches every session
+var (
+	sessions = monitor.NewRegistry()
+	bus      = monitor.NewBus(sessions)
+	usage    = monitor.NewUsage(0)
+	health   = monitor.NewHealth()
+)
+
+// drainTimeout is how long sessions get to finish when the server stops
+const drainTimeout = 30 * time.Second
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "eval" {
+		os.Exit(runEval(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "experiments" {
+		os.Exit(runExperiments())
+	}
+	if len(os.Args) > 1 && os.Args[1] == "feedback" {
+		os.Exit(runFeedback())
+	}
+
+	local := flag.Bool("local", false, "Run the TUI in this terminal instead of serving SSH")
+	contextDir := flag.String("context", "", "Project directory to attach as context (local mode)")
+	gitChanges := flag.Bool("git", false, "Attach the working tree diff and recent commits of the context directory (local mode)")
+	flag.Parse()
+
 	// Get port from env or default to 2222
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "2222"
 	}
 
+	// Load AI configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Anthropic.APIKey == "" {
+		log.Printf("No Anthropic API key configured, prompt generation disabled")
+	}
+	applyConfig(cfg)
+
+	if path, err := config.ExperimentLogPath(); err != nil {
+		log.Printf("Experiment outcomes won't be recorded: %v", err)
+	} else if outcomes, err = experiment.NewRecorder(path); err != nil {
+		log.Printf("Experiment outcomes won't be recorded: %v", err)
+	}
+	feedbackStore = openFeedback()
+	openLibrary()
+	openDrafts()
+	openHistory()
+	openSecretWords()
+
+	if *local {
+		runLocal(*contextDir, *gitChanges)
+		return
+	}
+
+	// Link shares and metrics are served over HTTP
+	openShares()
+	httpPort := os.Getenv("HTTP_PORT")
+	if httpPort == "" {
+		httpPort = "8080"
+	}
+	publicURL := os.Getenv("PUBLIC_URL")
+	if publicURL == "" {
+		publicURL = "http://localhost:" + httpPort
+	}
+	shareBaseURL = publicURL
+
 	// Get host key path from user's home directory
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -37,8 +138,11 @@ func main() {
 	s, err := wish.NewServer(
 		wish.WithAddress(":"+port),
 		wish.WithHostKeyPath(keyPath),
+		// Accept any key; it only identifies users for per-user state
+		wish.WithPublicKeyAuth(func(ssh.Context, ssh.PublicKey) bool { return true }),
 		wish.WithMiddleware(
-			bubbletea.Middleware(teaHandler),
+			bubbletea.MiddlewareWithProgramHandler(programHandler, termenv.Ascii),
+			commandMiddleware,
 			logging.Middleware(),
 		),
 	)
@@ -62,34 +166,244 @@ func main() {
 		}
 	}()
 
+	hs := newHTTPServer(httpPort)
+	if shares != nil {
+		log.Printf("   Shared prompts: %s/p/ID", publicURL)
+	}
+	log.Printf("   Metrics: %s/metrics", publicURL)
+	go func() {
+		if err := hs.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("HTTP server error: %v", err)
+		}
+	}()
+
 	// Wait for interrupt signal
 	<-done
 	log.Println("Shutting down server...")
 
-	// Graceful shutdown with 30-second timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Graceful shutdown: warn sessions, then give them drainTimeout to finish
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
 	defer cancel()
+	deadline, _ := ctx.Deadline()
+	if n := bus.Publish(tui.ShutdownMsg{At: deadline}); n > 0 {
+		log.Printf("Draining %d session(s)", n)
+	}
 
+	if err := hs.Shutdown(ctx); err != nil {
+		log.Printf("HTTP shutdown error: %v", err)
+	}
 	if err := s.Shutdown(ctx); err != nil {
-		log.Fatalf("Shutdown error: %v", err)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			log.Fatalf("Shutdown error: %v", err)
+		}
+		log.Printf("Closing %d session(s) still connected", len(sessions.List()))
 	}
 
 	log.Println("Server stopped")
 }
 
-// teaHandler creates a new Bubble Tea program for each SSH session
+// programHandler creates a Bubble Tea program for each SSH session and registers it for operators
+func programHandler(s ssh.Session) *tea.Program {
+	id := sessions.Add(monitor.SessionInfo{
+		User:    sessionUser(s),
+		Name:    s.User(),
+		Remote:  s.RemoteAddr().String(),
+		State:   "connecting",
+		Started: time.Now(),
+	}, s.Close)
+	go func() {
+		<-s.Context().Done()
+		sessions.Remove(id)
+	}()
+
+	m, opts := teaHandler(s)
+	p := tea.NewProgram(trackedModel{Model: m, id: id}, append(opts, bubbletea.MakeOptions(s)...)...)
+	sessions.SetProgram(id, p)
+	bus.Replay(p)
+	return p
+}
+
+// teaHandler creates the model for an SSH session: the admin dashboard for `ssh HOST admin`, otherwise the TUI
 func teaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
 	// Log the connection
 	log.Printf("New connection from %s", s.RemoteAddr())
 
-	// Create new TUI model for this session
-	m := tui.NewModel()
-
 	// Configure program options
 	opts := []tea.ProgramOption{
-		tea.WithAltScreen(),       // Use alternate screen buffer
-		tea.WithMouseCellMotion(), // Enable mouse support
+		tea.WithAltScreen(),        // Use alternate screen buffer
+		tea.WithMouseCellMotion(),  // Enable mouse support
+		tea.WithoutSignalHandler(), // Signals are for the server; sessions are drained on shutdown
+	}
+
+	// commandMiddleware only lets admins through with this command
+	if args := s.Command(); len(args) == 1 && args[0] == "admin" {
+		return admin.NewModel(adminServer()), opts
+	}
+
+	// Create new TUI model for this session
+	cur := current.Load()
+	user := sessionUser(s)
+	m := tui.NewModel(cur.enh, bubbletea.MakeRenderer(s)).
+		WithProjectContext(projects.Get(user)).
+		WithChanges(changes.Get(user)).
+		WithPrivacyPolicy(cur.privacy.BlockSecrets).
+		WithSecretWords(cur.secretGen, secretWords.Get(user), func(word string) {
+			if err := secretWords.Set(user, word); err != nil {
+				log.Printf("Failed to save secret word for %s: %v", user, err)
+			}
+		}).
+		WithExperiments(experiment.NewSession(cur.running, user, outcomes)).
+		WithFeedback(recordFeedback(user)).
+		WithCandidateStats(feedbackStore.CandidateStats).
+		WithHistory(historyStore, user).
+		WithWorkspaces(workspacesFor(user), library, s.User()).
+		WithUsage(func(model string, in, out int64) {
+			usage.Add(user, model, in, out)
+		}, func() bool {
+			return usage.Exceeded(user)
+		})
+	if shares != nil {
+		m = m.WithSharing(shares, user, shareBaseURL)
+	}
+	// Files are never written on the server; the user downloads them with the export command
+	m = m.WithRemoteSave(func(doc export.Document) {
+		exports.Set(user, &doc)
+	})
+	start, joined := pairingFor(s)
+	m = m.WithPairing(start, joined)
+	// A joined session shows the pairing session's work rather than offering the user's own draft
+	if drafts != nil && joined == nil {
+		m = m.WithDrafts(drafts, user)
 	}
 
 	return m, opts
 }
+
+// runLocal runs the TUI directly in the current terminal
+func runLocal(contextDir string, gitChanges bool) {
+	cur := current.Load()
+	m := tui.NewModel(cur.enh, nil).
+		WithPrivacyPolicy(cur.privacy.BlockSecrets).
+		WithSecretWords(cur.secretGen, config.LastSecretWord(), func(word string) {
+			if err := config.SaveLastSecretWord(word); err != nil {
+				log.Printf("Failed to save secret word: %v", err)
+			}
+		}).
+		WithExperiments(experiment.NewSession(cur.running, localUser(), outcomes)).
+		WithFeedback(recordFeedback(localUser())).
+		WithCandidateStats(feedbackStore.CandidateStats).
+		WithHistory(historyStore, localUser()).
+		WithWorkspaces(cur.workspaces, library, localUser()) // The local user owns the config, so sees every workspace
+	if drafts != nil {
+		m = m.WithDrafts(drafts, localUser())
+	}
+
+	if contextDir != "" {
+		pc, err := project.FromDir(contextDir)
+		if err != nil {
+			log.Fatalf("Failed to load project context: %v", err)
+		}
+		m = m.WithProjectContext(pc)
+	}
+
+	if gitChanges {
+		dir := contextDir
+		if dir == "" {
+			dir = "."
+		}
+		c, err := project.ChangesFromRepo(dir)
+		if err != nil {
+			log.Fatalf("Failed to read git changes: %v", err)
+		}
+		m = m.WithChanges(c)
+	}
+
+	if _, err := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion()).Run(); err != nil {
+		log.Fatalf("TUI error: %v", err)
+	}
+}
+
+// applyConfig builds the settings for new sessions from cfg
+func applyConfig(cfg *config.Config) {
+	next := &settings{
+		privacy:   cfg.Privacy,
+		secretGen: secret.NewGenerator(cfg.SecretWord.Adjectives, cfg.SecretWord.Nouns, cfg.SecretWord.AvoidCodeWords),
+		running:   experiments(cfg.Experiments),
+		admins:    cfg.Admin.Keys,
+	}
+	if cfg.Anthropic.APIKey != "" {
+		next.enh = newEnhancer(cfg, cfg.Anthropic.Model, cfg.Anthropic.MaxTokens, cfg.Anthropic.InputBudget)
+	}
+	next.workspaces = loadWorkspaces(cfg, next.enh != nil)
+
+	usage.SetQuota(cfg.Admin.TokenQuota)
+	current.Store(next)
+}
+
+// reloadConfig reads the config file again; sessions already connected keep their settings
+func reloadConfig() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	applyConfig(cfg)
+	log.Printf("Config reloaded")
+	return nil
+}
+
+// trackedModel reports the session's screen to the registry after every update
+type trackedModel struct {
+	tea.Model
+	id int
+}
+
+func (t trackedModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m, cmd := t.Model.Update(msg)
+	if s, ok := m.(interface{ StateName() string }); ok {
+		sessions.SetState(t.id, s.StateName())
+	}
+	t.Model = m
+	return t, cmd
+}
+
+// newEnhancer creates an enhancer for model with the configured routing and providers
+func newEnhancer(cfg *config.Config, model string, maxTokens int64, inputBudget int) *enhancer.Enhancer {
+	e := enhancer.NewEnhancer(cfg.Anthropic.APIKey, model)
+	e.SetLimits(maxTokens, inputBudget)
+	e.SetRouting(routing(cfg.Anthropic.Routing))
+	if cfg.OpenAI.APIKey != "" {
+		e.AddProvider("openai", ai.NewOpenAIProvider(cfg.OpenAI.APIKey, cfg.OpenAI.BaseURL))
+	}
+	e.SetObserver(health.Observe)
+	return e
+}
+
+// routing converts the routing config into the AI client's form
+func routing(rc config.RoutingConfig) ai.Routing {
+	r := ai.Routing{
+		Models: map[ai.Operation]string{
+			ai.OpAnalysis:   rc.Analysis,
+			ai.OpGeneration: rc.Generation,
+			ai.OpRefinement: rc.Refinement,
+			ai.OpCritique:   rc.Critique,
+		},
+		Fallbacks: rc.Fallbacks,
+	}
+	for _, rule := range rc.Rules {
+		r.Rules = append(r.Rules, ai.RoutingRule{
+			Operation:      ai.Operation(rule.Operation),
+			TaskType:       ai.TaskType(rule.TaskType),
+			MinInputTokens: rule.MinInputTokens,
+			Model:          rule.Model,
+		})
+	}
+	return r
+}
+
+// localUser identifies the local user for experiment assignment
+func localUser() string {
+	if u, err := user.Current(); err == nil {
+		return "local:" + u.Username
+	}
+	return "local"
+}
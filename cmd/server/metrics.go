@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// metricsHandler serves feedback aggregates, sessions and provider calls in the Prometheus text format
+func metricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writeMetrics(w)
+	})
+}
+
+// writeMetrics writes the current metrics to w
+func writeMetrics(w io.Writer) {
+	summaries := feedbackStore.Summaries()
+
+	metric(w, "promptgo_feedback_ratings_total", "counter", "Thumbs up and down on generated prompts by system prompt version")
+	for _, s := range summaries {
+		fmt.Fprintf(w, "promptgo_feedback_ratings_total{prompt_version=%s,rating=\"up\"} %d\n", label(s.PromptVersion), s.Up)
+		fmt.Fprintf(w, "promptgo_feedback_ratings_total{prompt_version=%s,rating=\"down\"} %d\n", label(s.PromptVersion), s.Down)
+	}
+	metric(w, "promptgo_feedback_comments_total", "counter", "Comments left with ratings by system prompt version")
+	for _, s := range summaries {
+		fmt.Fprintf(w, "promptgo_feedback_comments_total{prompt_version=%s} %d\n", label(s.PromptVersion), s.Comments)
+	}
+	metric(w, "promptgo_feedback_gate_answers_total", "counter", "Answers to whether the agent respected the secret-word gate by system prompt version")
+	for _, s := range summaries {
+		fmt.Fprintf(w, "promptgo_feedback_gate_answers_total{prompt_version=%s,respected=\"true\"} %d\n", label(s.PromptVersion), s.GateRespected)
+		fmt.Fprintf(w, "promptgo_feedback_gate_answers_total{prompt_version=%s,respected=\"false\"} %d\n", label(s.PromptVersion), s.GateAsked-s.GateRespected)
+	}
+
+	metric(w, "promptgo_sessions", "gauge", "Connected SSH sessions")
+	fmt.Fprintf(w, "promptgo_sessions %d\n", len(sessions.List()))
+
+	models := health.Models()
+	metric(w, "promptgo_model_calls_total", "counter", "Model calls by model")
+	for _, h := range models {
+		fmt.Fprintf(w, "promptgo_model_calls_total{model=%s} %d\n", label(h.Model), h.Calls)
+	}
+	metric(w, "promptgo_model_failures_total", "counter", "Failed model calls by model")
+	for _, h := range models {
+		fmt.Fprintf(w, "promptgo_model_failures_total{model=%s} %d\n", label(h.Model), h.Failures)
+	}
+}
+
+// metric writes the HELP and TYPE lines that precede a metric's samples
+func metric(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// labelEscaper escapes label values as the Prometheus text format requires
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// label quotes a label value
+func label(v string) string {
+	return `"` + labelEscaper.Replace(v) + `"`
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+
+	"promptgo/internal/collab"
+)
+
+// pairing holds the pairing sessions teammates join with `ssh -t HOST join CODE`
+var pairing = collab.NewHub()
+
+// handleJoinCommand checks the pairing code before the TUI starts
+func handleJoinCommand(s ssh.Session, args []string, next ssh.Handler) {
+	if len(args) != 1 {
+		wish.Fatalf(s, "Usage: ssh -t -p PORT HOST join CODE\n")
+		return
+	}
+	// ssh doesn't allocate a terminal when given a command, and the pairing TUI needs one
+	if _, _, ok := s.Pty(); !ok {
+		wish.Fatalf(s, "Error: join needs a terminal; connect with ssh -t, e.g. ssh -t -p PORT HOST join %s\n", args[0])
+		return
+	}
+	if !pairing.Exists(args[0]) {
+		wish.Fatalln(s, "Error:", collab.ErrNoRoom)
+		return
+	}
+	next(s)
+}
+
+// leaveOnDisconnect removes member from its pairing session when s ends
+func leaveOnDisconnect(s ssh.Session, member *collab.Member) *collab.Member {
+	go func() {
+		<-s.Context().Done()
+		member.Leave()
+	}()
+	return member
+}
+
+// pairingFor returns how the session starts pairing and, for `join CODE`, the session it joined
+func pairingFor(s ssh.Session) (func() (*collab.Member, error), *collab.Member) {
+	start := func() (*collab.Member, error) {
+		member, err := pairing.Create(sessionUser(s))
+		if err != nil {
+			return nil, err
+		}
+		return leaveOnDisconnect(s, member), nil
+	}
+
+	args := s.Command()
+	if len(args) != 2 || args[0] != "join" {
+		return start, nil
+	}
+	// The code was checked before the TUI started, but everyone may have left since
+	member, err := pairing.Join(args[1], sessionUser(s))
+	if err != nil {
+		return start, nil
+	}
+	return start, leaveOnDisconnect(s, member)
+}
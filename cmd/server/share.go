@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+
+	"promptgo/internal/config"
+	"promptgo/internal/share"
+)
+
+// shares holds prompts shared from SSH sessions; nil when the store couldn't be opened
+var shares *share.Store
+
+// openShares opens the share store
+func openShares() {
+	path, err := config.SharesPath()
+	if err == nil {
+		shares, err = share.NewStore(path)
+	}
+	if err != nil {
+		log.Printf("Sharing disabled: %v", err)
+	}
+}
+
+// newHTTPServer serves the metrics and, when sharing is enabled, read-only pages for link shares
+func newHTTPServer(port string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metricsHandler())
+	if shares != nil {
+		mux.Handle("/", share.Handler(shares))
+	}
+	return &http.Server{
+		Addr:              ":" + port,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+// workspaceIDs returns the IDs of the workspaces user is a member of
+func workspaceIDs(user string) []string {
+	var ids []string
+	for _, ws := range workspacesFor(user) {
+		ids = append(ids, ws.ID)
+	}
+	return ids
+}
+
+// handleShowCommand prints a shared prompt the user is allowed to see
+func handleShowCommand(s ssh.Session, args []string) {
+	if len(args) != 1 {
+		wish.Fatalf(s, "Usage: show ID\n")
+		return
+	}
+	if shares == nil {
+		wish.Fatalln(s, "Error: sharing is disabled on this server")
+		return
+	}
+
+	user := sessionUser(s)
+	sh, err := shares.Get(args[0])
+	// Shares the user can't see are reported as missing so IDs can't be probed
+	if err != nil || !sh.CanView(user, workspaceIDs(user)) {
+		wish.Fatalln(s, "Error:", share.ErrNotFound)
+		return
+	}
+	wish.Print(s, sh.Prompt)
+	if sh.Prompt != "" && sh.Prompt[len(sh.Prompt)-1] != '\n' {
+		wish.Println(s)
+	}
+}
+
+// handleSharesCommand lists the user's shares
+func handleSharesCommand(s ssh.Session) {
+	if shares == nil {
+		wish.Fatalln(s, "Error: sharing is disabled on this server")
+		return
+	}
+
+	owned := shares.Owned(sessionUser(s))
+	if len(owned) == 0 {
+		wish.Println(s, "You haven't shared any prompts")
+		return
+	}
+	now := time.Now()
+	for _, sh := range owned {
+		status := string(sh.Access)
+		switch {
+		case sh.Revoked:
+			status = "revoked"
+		case !sh.Live(now):
+			status = "expired"
+		case !sh.Expires.IsZero():
+			status += ", expires " + sh.Expires.Local().Format("2006-01-02 15:04")
+		}
+		wish.Printf(s, "%s  %s  %s  (%s)\n", sh.ID, sh.Created.Local().Format("2006-01-02"), sh.Title, status)
+	}
+}
+
+// handleRevokeCommand stops one of the user's shares from being viewed
+func handleRevokeCommand(s ssh.Session, args []string) {
+	if len(args) != 1 {
+		wish.Fatalf(s, "Usage: revoke ID\n")
+		return
+	}
+	if shares == nil {
+		wish.Fatalln(s, "Error: sharing is disabled on this server")
+		return
+	}
+
+	err := shares.Revoke(args[0], sessionUser(s))
+	if errors.Is(err, share.ErrForbidden) {
+		err = share.ErrNotFound // Don't confirm that someone else's ID exists
+	}
+	if err != nil {
+		wish.Fatalln(s, "Error:", err)
+		return
+	}
+	wish.Println(s, "Revoked", args[0])
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"log"
+
+	"promptgo/internal/config"
+	"promptgo/internal/draft"
+	"promptgo/internal/history"
+	"promptgo/internal/secret"
+)
+
+// drafts holds each user's autosaved work; nil when the store couldn't be opened
+var drafts *draft.Store
+
+// historyStore keeps every user's generated prompts, in memory only when the file couldn't be opened
+var historyStore *history.Store
+
+// secretWords remembers the last secret word each user chose, in memory only when the file couldn't be opened
+var secretWords *secret.WordStore
+
+// openDrafts opens the draft store
+func openDrafts() {
+	path, err := config.DraftsPath()
+	if err == nil {
+		drafts, err = draft.NewStore(path)
+	}
+	if err != nil {
+		log.Printf("Drafts won't be autosaved: %v", err)
+	}
+}
+
+// openHistory opens the history store, falling back to one that forgets on restart
+func openHistory() {
+	path, err := config.HistoryPath()
+	if err == nil {
+		historyStore, err = history.NewStore(path)
+	}
+	if err != nil {
+		log.Printf("History won't be kept across restarts: %v", err)
+		historyStore, _ = history.NewStore("")
+	}
+}
+
+// openSecretWords opens the secret word store, falling back to one that forgets on restart
+func openSecretWords() {
+	path, err := config.SecretWordsPath()
+	if err == nil {
+		secretWords, err = secret.NewWordStore(path)
+	}
+	if err != nil {
+		log.Printf("Secret words won't be remembered across restarts: %v", err)
+		secretWords, _ = secret.NewWordStore("")
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"promptgo/internal/config"
+	"promptgo/internal/enhancer"
+	"promptgo/internal/tui"
+	"promptgo/internal/workspace"
+)
+
+// library holds prompts published to workspaces; nil when it couldn't be opened
+var library *workspace.Library
+
+// openLibrary opens the shared workspace library
+func openLibrary() {
+	path, err := config.LibraryPath()
+	if err == nil {
+		library, err = workspace.NewLibrary(path)
+	}
+	if err != nil {
+		log.Printf("Workspace libraries disabled: %v", err)
+	}
+}
+
+// loadWorkspaces converts the workspace config; hasKey reports whether an API key is configured
+func loadWorkspaces(cfg *config.Config, hasKey bool) []tui.Workspace {
+	var out []tui.Workspace
+	for _, wc := range cfg.Workspaces {
+		ws := workspace.Workspace{
+			ID:          wc.ID,
+			Name:        wc.Name,
+			HouseRules:  wc.HouseRules,
+			Model:       wc.Model,
+			MaxTokens:   wc.MaxTokens,
+			InputBudget: wc.InputBudget,
+		}
+		if ws.Name == "" {
+			ws.Name = ws.ID
+		}
+		for _, m := range wc.Members {
+			// Usernames are chosen by the client, so only keys can prove membership
+			if strings.HasPrefix(m, "user:") {
+				log.Printf("Workspace %s: ignoring member %q, members are key fingerprints", ws.ID, m)
+				continue
+			}
+			ws.Members = append(ws.Members, m)
+		}
+		for _, tc := range wc.Templates {
+			ws.Templates = append(ws.Templates, workspace.Template{Name: tc.Name, Task: tc.Task, Details: tc.Details})
+		}
+		tw := tui.Workspace{Workspace: ws}
+		if hasKey {
+			tw.Enhancer = workspaceEnhancer(cfg, ws)
+		}
+		out = append(out, tw)
+	}
+	return out
+}
+
+// workspaceEnhancer returns an enhancer with the workspace's overrides, or nil to use the shared one
+func workspaceEnhancer(cfg *config.Config, ws workspace.Workspace) *enhancer.Enhancer {
+	if ws.Model == "" && ws.MaxTokens == 0 && ws.InputBudget == 0 {
+		return nil
+	}
+
+	model, maxTokens, inputBudget := cfg.Anthropic.Model, cfg.Anthropic.MaxTokens, cfg.Anthropic.InputBudget
+	if ws.Model != "" {
+		model = ws.Model
+	}
+	if ws.MaxTokens > 0 {
+		maxTokens = ws.MaxTokens
+	}
+	if ws.InputBudget > 0 {
+		inputBudget = ws.InputBudget
+	}
+
+	return newEnhancer(cfg, model, maxTokens, inputBudget)
+}
+
+// workspacesFor returns the workspaces user is a member of
+func workspacesFor(user string) []tui.Workspace {
+	var out []tui.Workspace
+	for _, ws := range current.Load().workspaces {
+		if ws.HasMember(user) {
+			out = append(out, ws)
+		}
+	}
+	return out
+}
@@ -0,0 +1,327 @@
+package admin
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"promptgo/internal/feedback"
+	"promptgo/internal/monitor"
+	"promptgo/internal/tui"
+)
+
+// refreshInterval is how often the dashboard reloads its data
+const refreshInterval = 2 * time.Second
+
+// Server is what the dashboard watches and acts on
+type Server struct {
+	Sessions *monitor.Registry
+	Usage    *monitor.Usage
+	Health   *monitor.Health
+	Feedback *feedback.Store
+	Bus      *monitor.Bus
+	Reload   func() error // Reloads the config file; applies to new sessions
+}
+
+// Broadcast shows text as a notice in every session, including ones that connect later
+func (s Server) Broadcast(text string) int {
+	return s.Bus.Announce(tui.NoticeMsg{Text: text})
+}
+
+// Maintenance warns every session that maintenance starts in d, with a countdown
+func (s Server) Maintenance(d time.Duration, text string) int {
+	if text == "" {
+		text = "Maintenance is starting, save your work"
+	}
+	return s.Bus.Announce(tui.NoticeMsg{Text: text, At: time.Now().Add(d)})
+}
+
+type refreshMsg time.Time
+
+// Model is the operator dashboard
+type Model struct {
+	server Server
+
+	sessions []monitor.SessionInfo
+	spend    []monitor.Spend
+	models   []monitor.ModelHealth
+	errors   []monitor.ErrorEntry
+	feedback []feedback.Summary
+	cursor   int // Selected session
+
+	broadcasting   bool
+	broadcastInput textinput.Model
+	status         string
+
+	width  int
+	height int
+}
+
+// NewModel creates a dashboard for server
+func NewModel(server Server) Model {
+	input := textinput.New()
+	input.Placeholder = "Message to every connected session"
+	input.CharLimit = 200
+	input.Width = 60
+	input.Cursor.Style = tui.CursorStyle()
+
+	m := Model{server: server, broadcastInput: input, width: 80, height: 24}
+	m.refresh()
+	return m
+}
+
+// tick schedules the next refresh
+func tick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg { return refreshMsg(t) })
+}
+
+// Init starts the refresh loop
+func (m Model) Init() tea.Cmd {
+	return tick()
+}
+
+// refresh reloads the dashboard data
+func (m *Model) refresh() {
+	m.sessions = m.server.Sessions.List()
+	m.spend = m.server.Usage.List()
+	m.models = m.server.Health.Models()
+	m.errors = m.server.Health.Errors()
+	m.feedback = m.server.Feedback.Summaries()
+	if m.cursor >= len(m.sessions) {
+		m.cursor = max(len(m.sessions)-1, 0)
+	}
+}
+
+// selected returns the highlighted session, or nil when none are connected
+func (m Model) selected() *monitor.SessionInfo {
+	if m.cursor < len(m.sessions) {
+		return &m.sessions[m.cursor]
+	}
+	return nil
+}
+
+// Update handles messages
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case refreshMsg:
+		m.refresh()
+		return m, tick()
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		if m.broadcasting {
+			return m.updateBroadcast(msg)
+		}
+		return m.updateDashboard(msg)
+	}
+	return m, nil
+}
+
+// updateDashboard handles keys on the dashboard
+func (m Model) updateDashboard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down", "j":
+		if m.cursor < len(m.sessions)-1 {
+			m.cursor++
+		}
+
+	case "x":
+		// Kick the selected session
+		if s := m.selected(); s != nil {
+			if err := m.server.Sessions.Kick(s.ID); err != nil {
+				m.status = "✗ " + err.Error()
+			} else {
+				m.status = fmt.Sprintf("✓ Kicked session %d (%s)", s.ID, s.Name)
+			}
+			m.refresh()
+		}
+
+	case "u":
+		// Reset the selected session's user quota
+		if s := m.selected(); s != nil {
+			m.server.Usage.Reset(s.User)
+			m.status = "✓ Reset quota for " + s.Name
+			m.refresh()
+		}
+
+	case "R":
+		// Reload the config file
+		if err := m.server.Reload(); err != nil {
+			m.status = "✗ Reload failed: " + err.Error()
+		} else {
+			m.status = "✓ Config reloaded; new sessions use it"
+		}
+		m.refresh()
+
+	case "b":
+		// Broadcast a message
+		m.broadcasting = true
+		m.broadcastInput.SetValue("")
+		return m, m.broadcastInput.Focus()
+
+	case "q":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// updateBroadcast handles the broadcast message input
+func (m Model) updateBroadcast(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.broadcasting = false
+		m.broadcastInput.Blur()
+		return m, nil
+
+	case tea.KeyEnter:
+		text := strings.TrimSpace(m.broadcastInput.Value())
+		m.broadcasting = false
+		m.broadcastInput.Blur()
+		// Sending waits for each program to take the message, so don't block the dashboard
+		server := m.server
+		go server.Broadcast(text)
+		if text == "" {
+			m.status = "✓ Cleared the notice in every session"
+		} else {
+			m.status = fmt.Sprintf("✓ Sent to %d session(s)", len(m.sessions))
+		}
+		return m, nil
+	}
+
+	m.broadcastInput, cmd = m.broadcastInput.Update(msg)
+	return m, cmd
+}
+
+// StateName labels the dashboard in the session list
+func (m Model) StateName() string {
+	return "admin"
+}
+
+// View renders the dashboard
+func (m Model) View() string {
+	var b strings.Builder
+
+	b.WriteString(tui.TitleStyle().Render("🐹 PromptGo - Admin"))
+	b.WriteString("\n\n")
+
+	// Sessions
+	b.WriteString(tui.FieldLabelStyle(true).Render(fmt.Sprintf("Sessions (%d)", len(m.sessions))))
+	b.WriteString("\n")
+	if len(m.sessions) == 0 {
+		b.WriteString(tui.SubtitleStyle().Render("  No one is connected"))
+		b.WriteString("\n")
+	}
+	for i, s := range m.sessions {
+		line := fmt.Sprintf("%3d  %-16s %-22s %-20s %s", s.ID, truncate(s.Name, 16), truncate(s.State, 22), s.Remote, time.Since(s.Started).Round(time.Second))
+		b.WriteString(tui.FieldLabelStyle(i == m.cursor).Render(line))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	// Token spend
+	quota := "no quota"
+	if q := m.server.Usage.Quota(); q > 0 {
+		quota = fmt.Sprintf("quota %d tokens", q)
+	}
+	b.WriteString(tui.FieldLabelStyle(true).Render("Token spend (" + quota + ")"))
+	b.WriteString("\n")
+	if len(m.spend) == 0 {
+		b.WriteString(tui.SubtitleStyle().Render("  Nothing spent yet"))
+		b.WriteString("\n")
+	}
+	for _, s := range m.spend {
+		b.WriteString(fmt.Sprintf("  %-24s %4d calls  %8d in  %8d out  $%.4f\n", truncate(s.User, 24), s.Calls, s.InputTokens, s.OutputTokens, s.Cost))
+	}
+	b.WriteString("\n")
+
+	// Provider health
+	b.WriteString(tui.FieldLabelStyle(true).Render("Providers"))
+	b.WriteString("\n")
+	if len(m.models) == 0 {
+		b.WriteString(tui.SubtitleStyle().Render("  No calls yet"))
+		b.WriteString("\n")
+	}
+	for _, h := range m.models {
+		mark := "✓"
+		if !h.Healthy() {
+			mark = "✗"
+		}
+		b.WriteString(fmt.Sprintf("  %s %-30s %4d calls  %3d failed  last %s\n", mark, h.Model, h.Calls, h.Failures, h.LastLatency.Round(time.Millisecond)))
+	}
+	b.WriteString("\n")
+
+	// Feedback per system prompt version
+	b.WriteString(tui.FieldLabelStyle(true).Render("Feedback"))
+	b.WriteString("\n")
+	if len(m.feedback) == 0 {
+		b.WriteString(tui.SubtitleStyle().Render("  None yet"))
+		b.WriteString("\n")
+	}
+	for _, f := range m.feedback {
+		version := f.PromptVersion
+		if version == "" {
+			version = "(offline)"
+		}
+		b.WriteString(fmt.Sprintf("  %-24s 👍 %-4d 👎 %-4d %3.0f%%  %4d comments  gate kept %d/%d\n", truncate(version, 24), f.Up, f.Down, 100*f.Approval(), f.Comments, f.GateRespected, f.GateAsked))
+	}
+	b.WriteString("\n")
+
+	// Recent errors
+	b.WriteString(tui.FieldLabelStyle(true).Render("Recent errors"))
+	b.WriteString("\n")
+	if len(m.errors) == 0 {
+		b.WriteString(tui.SubtitleStyle().Render("  None"))
+		b.WriteString("\n")
+	}
+	for i, e := range m.errors {
+		if i == 5 {
+			break
+		}
+		line := fmt.Sprintf("  %s %s: %s", e.Time.Format("15:04:05"), e.Source, e.Message)
+		b.WriteString(tui.ErrorStyle().Render(truncate(line, m.width-4)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if m.broadcasting {
+		b.WriteString(tui.FieldLabelStyle(true).Render("Broadcast:"))
+		b.WriteString(" ")
+		b.WriteString(m.broadcastInput.View())
+		b.WriteString("\n\n")
+		b.WriteString(tui.HelpStyle().Render("[Enter] Send (empty clears the notice)   [Esc] Cancel"))
+	} else {
+		if m.status != "" {
+			b.WriteString(tui.StatusBarStyle().Render(m.status))
+			b.WriteString("\n\n")
+		}
+		b.WriteString(tui.HelpStyle().Render("[↑/↓] Session   [x] Kick   [u] Reset quota   [b] Broadcast   [R] Reload config   [q] Quit"))
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// truncate shortens s to n runes
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if n < 1 || len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
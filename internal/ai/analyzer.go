@@ -17,44 +17,44 @@ const (
 	TypeOther         TaskType = "other"
 )
 
+// AnalysisRequest describes a task to classify
+type AnalysisRequest struct {
+	Task           string
+	Details        string
+	ProjectContext string // Summary of the user's codebase, optional
+	ChangeContext  string // Changed files, commits and test failures, optional
+	StackGuidance  string // Language and ecosystem the task targets, optional
+	PromptVersion  string // Analyzer system prompt version, empty for the default
+}
+
 type AnalysisResult struct {
-	TaskType  TaskType `json:"task_type"`
-	Questions []string `json:"questions"`
+	TaskType      TaskType `json:"task_type"`
+	Questions     []string `json:"questions"`
+	PromptVersion string   `json:"-"` // Analyzer system prompt version that produced this result
+	Model         string   `json:"-"`
+	InputTokens   int64    `json:"-"`
+	OutputTokens  int64    `json:"-"`
 }
 
 // AnalyzeTask analyzes a task and generates context-gathering questions
-func (c *Client) AnalyzeTask(ctx context.Context, task, details string) (*AnalysisResult, error) {
-	systemPrompt := `You are an expert software development assistant analyzing a developer's task.
-
-Your job:
-1. Classify the task type (feature, bugfix, testing, refactoring, documentation, or other)
-2. Generate 2-4 intelligent follow-up questions to gather context
-
-Questions should be:
-- Specific to the task type
-- Help understand constraints, existing architecture, preferences
-- Short and clear (one line each)
-- Answerable without project file access
-
-Return JSON only:
-{
-  "task_type": "feature|bugfix|testing|refactoring|documentation|other",
-  "questions": ["Question 1?", "Question 2?", ...]
-}`
+func (c *Client) AnalyzeTask(ctx context.Context, req AnalysisRequest) (*AnalysisResult, error) {
+	promptVersion, systemPrompt := SystemPrompt(PromptAnalyzer, req.PromptVersion)
 
 	userPrompt := fmt.Sprintf(`Task: %s
 
-Additional Details: %s
+Additional Details: %s%s%s
 
-Analyze this task and generate context questions.`, task, details)
+Analyze this task and generate context questions.`, req.Task, req.Details, stackSection(req.StackGuidance), projectSection(req.ProjectContext)+changesSection(req.ChangeContext))
 
-	response, err := c.SendMessage(ctx, systemPrompt, userPrompt)
+	resp, err := c.Send(ctx, systemPrompt, []Message{
+		{Role: RoleUser, Content: userPrompt},
+	}, CallOptions{Operation: OpAnalysis})
 	if err != nil {
 		return nil, fmt.Errorf("AI analysis failed: %w", err)
 	}
 
 	var result AnalysisResult
-	if err := json.Unmarshal([]byte(response), &result); err != nil {
+	if err := json.Unmarshal([]byte(resp.Text), &result); err != nil {
 		return nil, fmt.Errorf("failed to parse AI response: %w", err)
 	}
 
@@ -65,6 +65,8 @@ Analyze this task and generate context questions.`, task, details)
 			"Are there any existing patterns or conventions to follow?",
 		}
 	}
+	result.PromptVersion = promptVersion
+	result.Model, result.InputTokens, result.OutputTokens = resp.Model, resp.InputTokens, resp.OutputTokens
 
 	return &result, nil
 }
@@ -0,0 +1,77 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+// anthropicProvider sends requests to the Anthropic Messages API
+type anthropicProvider struct {
+	client *anthropic.Client
+}
+
+func newAnthropicProvider(apiKey string) *anthropicProvider {
+	client := anthropic.NewClient(
+		option.WithAPIKey(apiKey),
+	)
+	return &anthropicProvider{client: &client}
+}
+
+// Complete sends one request to Claude
+func (p *anthropicProvider) Complete(ctx context.Context, r ProviderRequest) (*Response, error) {
+	params := make([]anthropic.MessageParam, 0, len(r.Messages))
+	for _, msg := range r.Messages {
+		switch msg.Role {
+		case RoleUser:
+			params = append(params, anthropic.NewUserMessage(anthropic.NewTextBlock(msg.Content)))
+		case RoleAssistant:
+			params = append(params, anthropic.NewAssistantMessage(anthropic.NewTextBlock(msg.Content)))
+		}
+	}
+
+	req := anthropic.MessageNewParams{
+		Model:     anthropic.Model(r.Model),
+		MaxTokens: r.MaxTokens,
+		System: []anthropic.TextBlockParam{
+			{
+				Text: r.System,
+			},
+		},
+		Messages: params,
+	}
+	if r.Temperature != nil {
+		req.Temperature = anthropic.Float(*r.Temperature)
+	}
+
+	message, err := p.client.Messages.New(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract text from the response by marshaling and unmarshaling
+	if len(message.Content) > 0 {
+		// Use JSON to extract the text field
+		data, err := json.Marshal(message.Content[0])
+		if err == nil {
+			var result struct {
+				Text string `json:"text"`
+			}
+			if err := json.Unmarshal(data, &result); err == nil && result.Text != "" {
+				return &Response{
+					Text:         result.Text,
+					Model:        r.Model,
+					InputTokens:  message.Usage.InputTokens,
+					OutputTokens: message.Usage.OutputTokens,
+				}, nil
+			}
+		}
+
+		return nil, fmt.Errorf("unable to extract text from response")
+	}
+
+	return nil, fmt.Errorf("no content in response")
+}
@@ -2,63 +2,218 @@ package ai
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Role identifies who authored a message in a conversation
+type Role string
 
-	"github.com/anthropics/anthropic-sdk-go"
-	"github.com/anthropics/anthropic-sdk-go/option"
+const (
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
 )
 
+// Message is a single turn in a conversation with Claude
+type Message struct {
+	Role    Role
+	Content string
+}
+
+// DefaultMaxTokens bounds responses when neither the client nor the call sets a limit
+const DefaultMaxTokens = 2048
+
+// Provider sends a single completion request to a model API
+type Provider interface {
+	Complete(ctx context.Context, req ProviderRequest) (*Response, error)
+}
+
+// ProviderRequest is one call to one model
+type ProviderRequest struct {
+	Model       string
+	System      string
+	Messages    []Message
+	MaxTokens   int64
+	Temperature *float64  // Nil uses the API default
+	Operation   Operation // Informational; providers may ignore it
+}
+
 type Client struct {
-	client *anthropic.Client
-	model  anthropic.Model
+	provider  Provider
+	providers map[string]Provider // Other providers, whose models are named "provider:model"
+	model     string
+	maxTokens int64
+	routing   Routing
+	observer  func(CallEvent)
+}
+
+// CallEvent describes one provider call, including each fallback attempt
+type CallEvent struct {
+	Model     string
+	Operation Operation
+	Latency   time.Duration
+	Err       error // nil on success
 }
 
 // NewClient creates a new Anthropic API client
 func NewClient(apiKey string, model string) *Client {
-	client := anthropic.NewClient(
-		option.WithAPIKey(apiKey),
-	)
+	return NewClientWithProvider(newAnthropicProvider(apiKey), model)
+}
 
+// NewClientWithProvider creates a client that sends requests through provider
+func NewClientWithProvider(provider Provider, model string) *Client {
 	return &Client{
-		client: &client,
-		model:  anthropic.Model(model),
+		provider:  provider,
+		model:     model,
+		maxTokens: DefaultMaxTokens,
+	}
+}
+
+// AddProvider makes another provider's models available to routing and fallbacks as "name:model"
+func (c *Client) AddProvider(name string, p Provider) {
+	if c.providers == nil {
+		c.providers = make(map[string]Provider)
+	}
+	c.providers[name] = p
+}
+
+// resolve returns the provider that serves a model named in routing, and the model's name there
+func (c *Client) resolve(ref string) (Provider, string, error) {
+	name, model, ok := strings.Cut(ref, ":")
+	if !ok || !providerName(name) {
+		return c.provider, ref, nil
+	}
+	p, ok := c.providers[name]
+	if !ok {
+		return nil, "", fmt.Errorf("provider %q for model %q isn't configured", name, ref)
+	}
+	return p, model, nil
+}
+
+// providerName reports whether the text before a colon names a provider rather than being part
+// of a model ID, like the version suffix in "anthropic.claude-3-5-sonnet-20240620-v1:0"
+func providerName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// ModelName strips the provider from a model named in routing, e.g. "openai:gpt-4o" becomes "gpt-4o"
+func ModelName(ref string) string {
+	if name, model, ok := strings.Cut(ref, ":"); ok && providerName(name) {
+		return model
 	}
+	return ref
 }
 
-// SendMessage sends a message to Claude and returns the response
-func (c *Client) SendMessage(ctx context.Context, system string, user string) (string, error) {
-	message, err := c.client.Messages.New(ctx, anthropic.MessageNewParams{
-		Model:     c.model,
-		MaxTokens: 2048,
-		System: []anthropic.TextBlockParam{
-			{
-				Text: system,
-			},
-		},
-		Messages: []anthropic.MessageParam{
-			anthropic.NewUserMessage(anthropic.NewTextBlock(user)),
-		},
-	})
+// SetMaxTokens changes the default response limit; values below 1 restore DefaultMaxTokens
+func (c *Client) SetMaxTokens(n int64) {
+	if n < 1 {
+		n = DefaultMaxTokens
+	}
+	c.maxTokens = n
+}
+
+// MaxTokens returns the default response limit
+func (c *Client) MaxTokens() int64 {
+	return c.maxTokens
+}
+
+// SetObserver registers a function called after every provider call, e.g. to track provider health
+func (c *Client) SetObserver(fn func(CallEvent)) {
+	c.observer = fn
+}
+
+// Model returns the default model, used when routing doesn't pick another
+func (c *Client) Model() string {
+	return c.model
+}
+
+// CallOptions overrides client defaults for a single request
+type CallOptions struct {
+	Model       string   // Empty routes by operation, falling back to the client's model
+	Temperature *float64 // Nil uses the API default
+	MaxTokens   int64    // Zero uses the client's limit
+
+	Operation Operation // Used for routing, empty for the default model
+	TaskType  TaskType  // Used for routing rules
+}
+
+// Response is the text of a model response along with its token usage
+type Response struct {
+	Text         string
+	Model        string
+	InputTokens  int64
+	OutputTokens int64
+}
+
+// SendMessage sends a conversation to Claude and returns the response
+func (c *Client) SendMessage(ctx context.Context, system string, messages []Message) (string, error) {
+	resp, err := c.Send(ctx, system, messages, CallOptions{})
 	if err != nil {
 		return "", err
 	}
+	return resp.Text, nil
+}
 
-	// Extract text from the response by marshaling and unmarshaling
-	if len(message.Content) > 0 {
-		// Use JSON to extract the text field
-		data, err := json.Marshal(message.Content[0])
-		if err == nil {
-			var result struct {
-				Text string `json:"text"`
-			}
-			if err := json.Unmarshal(data, &result); err == nil && result.Text != "" {
-				return result.Text, nil
-			}
+// Send sends a conversation to Claude with per-call options and returns the response with usage
+func (c *Client) Send(ctx context.Context, system string, messages []Message, opts CallOptions) (*Response, error) {
+	for _, msg := range messages {
+		if msg.Role != RoleUser && msg.Role != RoleAssistant {
+			return nil, fmt.Errorf("unknown message role %q", msg.Role)
 		}
+	}
+
+	model := opts.Model
+	if model == "" {
+		inputTokens := EstimateTokens(c.model, system)
+		for _, msg := range messages {
+			inputTokens += EstimateTokens(c.model, msg.Content)
+		}
+		model = c.route(opts.Operation, opts.TaskType, inputTokens)
+	}
 
-		return "", fmt.Errorf("unable to extract text from response")
+	maxTokens := c.maxTokens
+	if opts.MaxTokens > 0 {
+		maxTokens = opts.MaxTokens
 	}
 
-	return "", fmt.Errorf("no content in response")
+	var lastErr error
+	for _, m := range c.modelChain(model) {
+		provider, name, err := c.resolve(m)
+		if err != nil {
+			lastErr = err
+			log.Printf("Skipping model %s: %v", m, err)
+			continue
+		}
+		start := time.Now()
+		resp, err := provider.Complete(ctx, ProviderRequest{
+			Model:       name,
+			System:      system,
+			Messages:    messages,
+			MaxTokens:   maxTokens,
+			Temperature: opts.Temperature,
+			Operation:   opts.Operation,
+		})
+		if c.observer != nil {
+			c.observer(CallEvent{Model: m, Operation: opts.Operation, Latency: time.Since(start), Err: err})
+		}
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if !retryable(ctx, err) {
+			break
+		}
+		log.Printf("Model %s failed, trying the next fallback: %v", m, err)
+	}
+	return nil, lastErr
 }
@@ -0,0 +1,28 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// CritiquePrompt has the critique model review a generated prompt and return an improved version
+func (c *Client) CritiquePrompt(ctx context.Context, req PromptRequest, prompt string) (*Generation, error) {
+	version, system := SystemPrompt(PromptCritique, "")
+	resp, err := c.Send(ctx, system, []Message{
+		{Role: RoleUser, Content: generationUserPrompt(req)},
+		{Role: RoleAssistant, Content: prompt},
+		{Role: RoleUser, Content: fmt.Sprintf("Review and improve this prompt. The secret word is %q.", req.SecretWord)},
+	}, CallOptions{Operation: OpCritique, TaskType: req.TaskType})
+	if err != nil {
+		return nil, fmt.Errorf("prompt critique failed: %w", err)
+	}
+
+	return &Generation{
+		Prompt:        strings.ReplaceAll(resp.Text, "{{SECRET_WORD}}", req.SecretWord),
+		Model:         resp.Model,
+		InputTokens:   resp.InputTokens,
+		OutputTokens:  resp.OutputTokens,
+		PromptVersion: version,
+	}, nil
+}
@@ -12,27 +12,69 @@ type PromptRequest struct {
 	TaskType   TaskType
 	QA         map[string]string // Question -> Answer
 	SecretWord string
+
+	ProjectContext string // Size-bounded summary of the user's codebase, optional
+	APIMap         string // Go API relevant to the task: interfaces, signatures, error types, test conventions
+	ChangeContext  string // Changed files, commits and test failures, optional
+	StackGuidance  string // Language and ecosystem the task targets, optional
+	PromptVersion  string // Generator system prompt version, empty for the default
+}
+
+// GenerateOptions tunes a single prompt generation
+type GenerateOptions struct {
+	CallOptions
+	Template string // Methodology template the prompt should follow, empty for a fully custom prompt
+}
+
+// Generation is a generated prompt along with the usage of the call that produced it
+type Generation struct {
+	Prompt        string
+	Model         string
+	InputTokens   int64
+	OutputTokens  int64
+	PromptVersion string // System prompt version that produced it
 }
 
 // GeneratePrompt generates a comprehensive, task-specific prompt
 func (c *Client) GeneratePrompt(ctx context.Context, req PromptRequest) (string, error) {
-	systemPrompt := `You are an expert prompt engineer for software development.
+	gen, err := c.GeneratePromptWith(ctx, req, GenerateOptions{})
+	if err != nil {
+		return "", err
+	}
+	return gen.Prompt, nil
+}
 
-Generate a comprehensive, structured prompt that guides a developer through implementing their task.
+// GeneratePromptWith generates a prompt using per-call model, temperature and template overrides
+func (c *Client) GeneratePromptWith(ctx context.Context, req PromptRequest, opts GenerateOptions) (*Generation, error) {
+	version, system := SystemPrompt(PromptGenerator, req.PromptVersion)
+	if opts.Template != "" {
+		system += "\n\nBase the structure of the prompt on this methodology template, adapting it to the task:\n\n" + opts.Template
+	}
 
-The prompt should:
-1. Be specific to the task type and context provided
-2. Follow a phase-based methodology:
-   - PHASE 1: UNDERSTAND - Deep analysis, propose approaches, ask clarifying questions
-   - PHASE 2: ALIGN - Design review, sketch requirements, implementation planning
-   - PHASE 3: BUILD - Implementation (gated by secret word: "{{SECRET_WORD}}")
-3. Incorporate the context from the Q&A
-4. Include task-specific best practices
-5. Suggest testing strategies appropriate for the task
-6. Be practical and actionable
+	if opts.Operation == "" {
+		opts.Operation = OpGeneration
+	}
+	opts.TaskType = req.TaskType
 
-Do not use generic templates. Create a fully custom prompt tailored to THIS specific task.`
+	resp, err := c.Send(ctx, system, []Message{
+		{Role: RoleUser, Content: generationUserPrompt(req)},
+	}, opts.CallOptions)
+	if err != nil {
+		return nil, fmt.Errorf("prompt generation failed: %w", err)
+	}
 
+	return &Generation{
+		// Replace the placeholder with the actual secret word in the response
+		Prompt:        strings.ReplaceAll(resp.Text, "{{SECRET_WORD}}", req.SecretWord),
+		Model:         resp.Model,
+		InputTokens:   resp.InputTokens,
+		OutputTokens:  resp.OutputTokens,
+		PromptVersion: version,
+	}, nil
+}
+
+// generationUserPrompt builds the user message describing the task to generate a prompt for
+func generationUserPrompt(req PromptRequest) string {
 	// Build context section from Q&A
 	qaContext := ""
 	if len(req.QA) > 0 {
@@ -42,20 +84,42 @@ Do not use generic templates. Create a fully custom prompt tailored to THIS spec
 		}
 	}
 
-	userPrompt := fmt.Sprintf(`Task Type: %s
+	return fmt.Sprintf(`Task Type: %s
 Task: %s
-Details: %s%s
+Details: %s%s%s
 Secret Word: %s
 
-Generate the enhanced prompt now.`, req.TaskType, req.Task, req.Details, qaContext, req.SecretWord)
+Generate the enhanced prompt now.`, req.TaskType, req.Task, req.Details, qaContext, stackSection(req.StackGuidance)+projectSection(req.ProjectContext)+apiMapSection(req.APIMap)+changesSection(req.ChangeContext), req.SecretWord)
+}
 
-	response, err := c.SendMessage(ctx, systemPrompt, userPrompt)
-	if err != nil {
-		return "", fmt.Errorf("prompt generation failed: %w", err)
+// stackSection formats optional stack pack guidance for a user message
+func stackSection(guidance string) string {
+	if guidance == "" {
+		return ""
 	}
+	return "\n\n" + guidance
+}
 
-	// Replace the placeholder with the actual secret word in the response
-	response = strings.ReplaceAll(response, "{{SECRET_WORD}}", req.SecretWord)
+// changesSection formats optional diff, commit and test failure context for a user message
+func changesSection(changeContext string) string {
+	if changeContext == "" {
+		return ""
+	}
+	return "\n\nRecent Changes and Failures:\n" + changeContext
+}
 
-	return response, nil
+// apiMapSection formats an optional Go API map for a user message
+func apiMapSection(apiMap string) string {
+	if apiMap == "" {
+		return ""
+	}
+	return "\n\nGo API Map (extend these rather than inventing parallel types):\n" + apiMap
+}
+
+// projectSection formats an optional project summary for a user message
+func projectSection(projectContext string) string {
+	if projectContext == "" {
+		return ""
+	}
+	return "\n\nProject Context:\n" + projectContext
 }
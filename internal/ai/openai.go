@@ -0,0 +1,122 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultOpenAIURL is the OpenAI API; other services compatible with its chat completions can be used instead
+const DefaultOpenAIURL = "https://api.openai.com/v1"
+
+// StatusError is an HTTP error from a provider without an error type of its own
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.StatusCode, e.Message)
+}
+
+// openAIProvider sends requests to the OpenAI chat completions API
+type openAIProvider struct {
+	apiKey  string
+	baseURL string
+	http    *http.Client
+}
+
+// NewOpenAIProvider creates a provider for the OpenAI chat completions API at baseURL, empty for DefaultOpenAIURL
+func NewOpenAIProvider(apiKey, baseURL string) Provider {
+	if baseURL == "" {
+		baseURL = DefaultOpenAIURL
+	}
+	return &openAIProvider{apiKey: apiKey, baseURL: strings.TrimRight(baseURL, "/"), http: http.DefaultClient}
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	MaxTokens   int64           `json:"max_tokens,omitempty"`
+	Temperature *float64        `json:"temperature,omitempty"`
+}
+
+type openAIResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int64 `json:"prompt_tokens"`
+		CompletionTokens int64 `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Complete sends one request to the chat completions endpoint
+func (p *openAIProvider) Complete(ctx context.Context, r ProviderRequest) (*Response, error) {
+	body := openAIRequest{
+		Model:       r.Model,
+		Messages:    []openAIMessage{{Role: "system", Content: r.System}},
+		MaxTokens:   r.MaxTokens,
+		Temperature: r.Temperature,
+	}
+	for _, msg := range r.Messages {
+		body.Messages = append(body.Messages, openAIMessage{Role: string(msg.Role), Content: msg.Content})
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/chat/completions", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err = io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	var result openAIResponse
+	parseErr := json.Unmarshal(data, &result)
+	if resp.StatusCode != http.StatusOK {
+		message := strings.TrimSpace(string(data))
+		if parseErr == nil && result.Error != nil {
+			message = result.Error.Message
+		}
+		return nil, &StatusError{StatusCode: resp.StatusCode, Message: message}
+	}
+	if parseErr != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", parseErr)
+	}
+	if len(result.Choices) == 0 || result.Choices[0].Message.Content == "" {
+		return nil, fmt.Errorf("no content in response")
+	}
+
+	return &Response{
+		Text:         result.Choices[0].Message.Content,
+		Model:        r.Model,
+		InputTokens:  result.Usage.PromptTokens,
+		OutputTokens: result.Usage.CompletionTokens,
+	}, nil
+}
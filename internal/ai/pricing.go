@@ -0,0 +1,16 @@
+package ai
+
+// modelPricing is the USD price per million input and output tokens
+var modelPricing = map[string]struct{ input, output float64 }{
+	"claude-3-5-haiku-20241022":  {input: 0.80, output: 4.00},
+	"claude-3-5-sonnet-20241022": {input: 3.00, output: 15.00},
+}
+
+// EstimateCost returns the USD cost of a call, or false if the model's pricing is unknown
+func EstimateCost(model string, inputTokens, outputTokens int64) (float64, bool) {
+	price, ok := modelPricing[ModelName(model)]
+	if !ok {
+		return 0, false
+	}
+	return (float64(inputTokens)*price.input + float64(outputTokens)*price.output) / 1_000_000, true
+}
@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"embed"
+	"path"
+	"sort"
+	"strings"
+)
+
+// System prompts are versioned assets in prompts/, named <kind>-<version>.txt.
+// Add a new file rather than editing a released one, so results stay attributable to a version.
+//
+//go:embed prompts/*.txt
+var promptFiles embed.FS
+
+// Prompt kinds
+const (
+	PromptAnalyzer  = "analyzer"
+	PromptGenerator = "generator"
+	PromptCritique  = "critique"
+	PromptSummary   = "summary"
+)
+
+// DefaultPromptVersions is the version of each kind used when a request doesn't pick one
+var DefaultPromptVersions = map[string]string{
+	PromptAnalyzer:  "analyzer-v1",
+	PromptGenerator: "generator-v1",
+	PromptCritique:  "critique-v1",
+	PromptSummary:   "summary-v1",
+}
+
+// prompts maps version IDs to their text
+var prompts = loadPrompts()
+
+func loadPrompts() map[string]string {
+	entries, err := promptFiles.ReadDir("prompts")
+	if err != nil {
+		panic(err)
+	}
+	m := make(map[string]string, len(entries))
+	for _, e := range entries {
+		data, err := promptFiles.ReadFile(path.Join("prompts", e.Name()))
+		if err != nil {
+			panic(err)
+		}
+		m[strings.TrimSuffix(e.Name(), ".txt")] = strings.TrimRight(string(data), "\n")
+	}
+	return m
+}
+
+// SystemPrompt returns the version ID and text of a system prompt. Unknown IDs, or IDs of
+// another kind, fall back to the kind's default so a bad experiment config can't break requests.
+func SystemPrompt(kind, id string) (string, string) {
+	if text, ok := prompts[id]; ok && strings.HasPrefix(id, kind+"-") {
+		return id, text
+	}
+	id = DefaultPromptVersions[kind]
+	return id, prompts[id]
+}
+
+// PromptVersions lists the available versions of a kind
+func PromptVersions(kind string) []string {
+	var ids []string
+	for id := range prompts {
+		if strings.HasPrefix(id, kind+"-") {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,65 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Revision is one version of a generated prompt
+type Revision struct {
+	Prompt   string
+	Feedback string // Feedback that produced this revision, empty for the initial generation
+	Model    string // Model that produced this revision, empty for offline output
+
+	PromptVersion string // System prompt version that produced this revision, empty for offline output
+
+	HumanEdited bool // The user edited this revision by hand
+}
+
+// RefinePrompt revises the latest revision using the user's feedback.
+// Earlier revisions are replayed as a conversation so the model sees how the prompt evolved.
+func (c *Client) RefinePrompt(ctx context.Context, req PromptRequest, revisions []Revision, feedback string) (*Generation, error) {
+	if len(revisions) == 0 {
+		return nil, errors.New("no prompt to refine")
+	}
+
+	messages := []Message{
+		{Role: RoleUser, Content: generationUserPrompt(req)},
+		{Role: RoleAssistant, Content: revisions[0].Prompt},
+	}
+	for _, rev := range revisions[1:] {
+		request := refinementUserPrompt(rev.Feedback)
+		if rev.HumanEdited {
+			// Hand edits weren't written by the model, so present them as coming from the user
+			request = "I edited the prompt by hand. Treat this as the current version and return it unchanged:\n\n" + rev.Prompt
+		}
+		messages = append(messages,
+			Message{Role: RoleUser, Content: request},
+			Message{Role: RoleAssistant, Content: rev.Prompt},
+		)
+	}
+	messages = append(messages, Message{Role: RoleUser, Content: refinementUserPrompt(feedback)})
+
+	version, system := SystemPrompt(PromptGenerator, req.PromptVersion)
+	resp, err := c.Send(ctx, system, messages, CallOptions{Operation: OpRefinement, TaskType: req.TaskType})
+	if err != nil {
+		return nil, fmt.Errorf("prompt refinement failed: %w", err)
+	}
+
+	return &Generation{
+		Prompt:        strings.ReplaceAll(resp.Text, "{{SECRET_WORD}}", req.SecretWord),
+		Model:         resp.Model,
+		InputTokens:   resp.InputTokens,
+		OutputTokens:  resp.OutputTokens,
+		PromptVersion: version,
+	}, nil
+}
+
+// refinementUserPrompt wraps user feedback so the model returns a complete revised prompt
+func refinementUserPrompt(feedback string) string {
+	return fmt.Sprintf(`Revise the prompt based on this feedback: %s
+
+Return the complete revised prompt only, keeping the secret word gate intact.`, feedback)
+}
@@ -0,0 +1,148 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// Operation is a kind of call, each of which can be routed to its own model
+type Operation string
+
+const (
+	OpAnalysis   Operation = "analysis"
+	OpGeneration Operation = "generation"
+	OpRefinement Operation = "refinement" // Falls back to the generation model
+	OpCritique   Operation = "critique"   // Optional review pass, off unless a model is set
+	OpSummary    Operation = "summary"    // Condenses context over budget; falls back to the analysis model
+)
+
+// RoutingRule picks a model for an operation when the task type and input size match.
+// Empty TaskType and zero MinInputTokens match anything.
+type RoutingRule struct {
+	Operation      Operation
+	TaskType       TaskType
+	MinInputTokens int
+	Model          string
+}
+
+// Routing chooses models per operation and what to fall back to when a model fails. Models of
+// providers added with AddProvider are named "provider:model", e.g. "openai:gpt-4o".
+type Routing struct {
+	Models    map[Operation]string
+	Rules     []RoutingRule // First match wins, ahead of Models
+	Fallbacks []string      // Tried in order when the routed model is overloaded or erroring
+}
+
+// SetRouting replaces the client's routing; the client's model remains the default
+func (c *Client) SetRouting(r Routing) {
+	c.routing = r
+}
+
+// HasCritique reports whether a critique model is configured
+func (c *Client) HasCritique() bool {
+	if c.routing.Models[OpCritique] != "" {
+		return true
+	}
+	for _, r := range c.routing.Rules {
+		if r.Operation == OpCritique {
+			return true
+		}
+	}
+	return false
+}
+
+// route picks the model for a call
+func (c *Client) route(op Operation, taskType TaskType, inputTokens int) string {
+	for _, r := range c.routing.Rules {
+		if r.Operation != op {
+			continue
+		}
+		if r.TaskType != "" && r.TaskType != taskType {
+			continue
+		}
+		if inputTokens < r.MinInputTokens {
+			continue
+		}
+		return r.Model
+	}
+	if m := c.routing.Models[op]; m != "" {
+		return m
+	}
+	switch op {
+	case OpRefinement:
+		return c.route(OpGeneration, taskType, inputTokens)
+	case OpSummary:
+		return c.route(OpAnalysis, taskType, inputTokens)
+	}
+	return c.model
+}
+
+// RoutedModel returns the model a call for op would go to, before any fallback
+func (c *Client) RoutedModel(op Operation, taskType TaskType, inputTokens int) string {
+	return c.route(op, taskType, inputTokens)
+}
+
+// Models lists the models op may use: the one it is routed to by default, those its rules route
+// to, then the fallbacks. Models of providers that aren't configured are left out.
+func (c *Client) Models(op Operation) []string {
+	var models []string
+	add := func(m string) {
+		if m == "" || slices.Contains(models, m) {
+			return
+		}
+		if _, _, err := c.resolve(m); err == nil {
+			models = append(models, m)
+		}
+	}
+	add(c.route(op, "", 0))
+	for _, r := range c.routing.Rules {
+		if r.Operation == op {
+			add(r.Model)
+		}
+	}
+	for _, m := range c.routing.Fallbacks {
+		add(m)
+	}
+	return models
+}
+
+// modelChain lists the routed model followed by its fallbacks, without repeats
+func (c *Client) modelChain(model string) []string {
+	chain := []string{model}
+	for _, m := range c.routing.Fallbacks {
+		if m != "" && !slices.Contains(chain, m) {
+			chain = append(chain, m)
+		}
+	}
+	return chain
+}
+
+// retryable reports whether another model might succeed where this call failed.
+// Client errors like a bad request or invalid key would fail on every model.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var status int
+	var apiErr *anthropic.Error
+	var statusErr *StatusError
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.StatusCode
+	case errors.As(err, &statusErr):
+		status = statusErr.StatusCode
+	default:
+		return true // Network failures and unusable responses
+	}
+	switch {
+	case status == http.StatusTooManyRequests,
+		status == http.StatusNotFound, // Model retired or unavailable to this key
+		status >= 500:                 // Includes 529 overloaded
+		return true
+	}
+	return false
+}
@@ -0,0 +1,18 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+)
+
+// Summarize condenses context that doesn't fit the token budget to at most maxTokens tokens
+func (c *Client) Summarize(ctx context.Context, task, text string, maxTokens int) (*Response, error) {
+	_, system := SystemPrompt(PromptSummary, "")
+	resp, err := c.Send(ctx, system, []Message{
+		{Role: RoleUser, Content: fmt.Sprintf("Task: %s\n\nCondense this to at most %d tokens:\n\n%s", task, maxTokens, text)},
+	}, CallOptions{Operation: OpSummary, MaxTokens: int64(maxTokens)})
+	if err != nil {
+		return nil, fmt.Errorf("summary failed: %w", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,57 @@
+package ai
+
+import "strings"
+
+// charsPerToken is the average characters per token for each provider's tokenizer, keyed by model prefix.
+// These are rough: English prose and code both land near these figures.
+var charsPerToken = []struct {
+	prefix string
+	chars  float64
+}{
+	{prefix: "claude", chars: 3.5},
+	{prefix: "gpt", chars: 4.0},
+}
+
+const defaultCharsPerToken = 4.0
+
+// contextWindows is the input and output tokens each model family accepts, keyed by model prefix
+var contextWindows = []struct {
+	prefix string
+	tokens int
+}{
+	{prefix: "claude", tokens: 200000},
+	{prefix: "gpt-4.1", tokens: 1047576},
+	{prefix: "gpt-4o", tokens: 128000},
+	{prefix: "gpt-4-turbo", tokens: 128000},
+	{prefix: "gpt-3.5-turbo", tokens: 16385},
+}
+
+// EstimateTokens approximates how many tokens text uses with a model
+func EstimateTokens(model, text string) int {
+	if text == "" {
+		return 0
+	}
+	return int(float64(len(text))/CharsPerToken(model)) + 1
+}
+
+// CharsPerToken returns the average characters per token for a model's provider
+func CharsPerToken(model string) float64 {
+	model = ModelName(model)
+	for _, c := range charsPerToken {
+		if strings.HasPrefix(model, c.prefix) {
+			return c.chars
+		}
+	}
+	return defaultCharsPerToken
+}
+
+// ContextWindow returns how many tokens a model accepts across input and output, or 0 when unknown
+func ContextWindow(model string) int {
+	model = ModelName(model)
+	for _, w := range contextWindows {
+		if strings.HasPrefix(model, w.prefix) {
+			return w.tokens
+		}
+	}
+	return 0
+}
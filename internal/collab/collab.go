@@ -0,0 +1,396 @@
+package collab
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"promptgo/internal/ai"
+	"promptgo/internal/enhancer"
+)
+
+// turnIdle is how long the editor must be idle before someone else can take the turn without asking
+const turnIdle = 10 * time.Second
+
+// typingWindow is how recently a participant must have edited to show as typing
+const typingWindow = 3 * time.Second
+
+var (
+	ErrNoRoom      = errors.New("no pairing session with that code")
+	ErrNotYourTurn = errors.New("it's someone else's turn to edit")
+	ErrConflict    = errors.New("someone else changed it first")
+)
+
+// Field is a shared input field
+type Field int
+
+const (
+	FieldTask Field = iota
+	FieldDetails
+	FieldSecretWord
+	numFields
+)
+
+// Text is the shared value of a field
+type Text struct {
+	Value   string
+	Version int    // Bumped on every edit; edits must be based on the latest version
+	Author  string // Who made the last edit
+}
+
+// Result is the shared generated prompt
+type Result struct {
+	Input     enhancer.Input // Input the revisions were generated from
+	TaskType  ai.TaskType
+	QA        map[string]string // Answered questions
+	Revisions []ai.Revision
+	Index     int // Revision on screen
+	Tip       string
+}
+
+// Participant is someone in a pairing session
+type Participant struct {
+	ID        int
+	Name      string
+	View      string // Screen they're on
+	LastEdit  time.Time
+	WantsTurn bool
+}
+
+// Typing reports whether the participant edited a field in the last few seconds
+func (p Participant) Typing() bool {
+	return time.Since(p.LastEdit) < typingWindow
+}
+
+// Snapshot is the state of a pairing session, sent to every participant whenever it changes
+type Snapshot struct {
+	Code          string
+	Turn          int // ID of the participant whose turn it is to edit
+	Participants  []Participant
+	Fields        [numFields]Text
+	Result        *Result // nil until a prompt is generated
+	ResultVersion int
+	Event         string // What last happened, e.g. "bob joined"
+}
+
+// Holder returns the participant whose turn it is, or nil
+func (s Snapshot) Holder() *Participant {
+	for i := range s.Participants {
+		if s.Participants[i].ID == s.Turn {
+			return &s.Participants[i]
+		}
+	}
+	return nil
+}
+
+// Hub holds the pairing sessions running on the server
+type Hub struct {
+	mu    sync.Mutex
+	rooms map[string]*room
+}
+
+// NewHub creates a hub with no sessions
+func NewHub() *Hub {
+	return &Hub{rooms: make(map[string]*room)}
+}
+
+// normalize makes codes case-insensitive
+func normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// newCode generates a short code that is easy to read out, like K7QX-2MBA
+func newCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	s := base32.StdEncoding.EncodeToString(b)
+	return s[:4] + "-" + s[4:], nil
+}
+
+// Create starts a pairing session with name as its first participant, who gets the first turn
+func (h *Hub) Create(name string) (*Member, error) {
+	h.mu.Lock()
+	var code string
+	for code == "" || h.rooms[code] != nil {
+		var err error
+		if code, err = newCode(); err != nil {
+			h.mu.Unlock()
+			return nil, fmt.Errorf("failed to generate pairing code: %w", err)
+		}
+	}
+	r := &room{hub: h, code: code}
+	h.rooms[code] = r
+	h.mu.Unlock()
+
+	// Nobody else knows the code yet, so the room can't have closed
+	m, _ := r.join(name, name+" started pairing")
+	return m, nil
+}
+
+// Join adds name to the pairing session with code
+func (h *Hub) Join(code, name string) (*Member, error) {
+	h.mu.Lock()
+	r := h.rooms[normalize(code)]
+	h.mu.Unlock()
+	if r == nil {
+		return nil, ErrNoRoom
+	}
+	return r.join(name, name+" joined")
+}
+
+// Exists reports whether a pairing session with code is running
+func (h *Hub) Exists(code string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.rooms[normalize(code)] != nil
+}
+
+// remove forgets an empty room
+func (h *Hub) remove(code string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.rooms, code)
+}
+
+// room is one pairing session
+type room struct {
+	hub  *Hub
+	code string
+
+	mu            sync.Mutex
+	next          int
+	members       []*Member // In join order, which is also the turn order
+	turn          int
+	fields        [numFields]Text
+	result        *Result
+	resultVersion int
+	closed        bool // Everyone left
+}
+
+// Member is one participant's handle on a pairing session
+type Member struct {
+	room    *room
+	info    Participant
+	updates chan Snapshot
+}
+
+// join adds a participant and tells everyone
+func (r *room) join(name, event string) (*Member, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		// Everyone left between looking the room up and joining it
+		return nil, ErrNoRoom
+	}
+	r.next++
+	m := &Member{room: r, info: Participant{ID: r.next, Name: name, View: "input"}, updates: make(chan Snapshot, 1)}
+	r.members = append(r.members, m)
+	if len(r.members) == 1 {
+		r.turn = m.info.ID
+	}
+	r.broadcast(event)
+	return m, nil
+}
+
+// snapshot returns the room's state; callers hold the lock
+func (r *room) snapshot(event string) Snapshot {
+	s := Snapshot{
+		Code:          r.code,
+		Turn:          r.turn,
+		Fields:        r.fields,
+		ResultVersion: r.resultVersion,
+		Event:         event,
+	}
+	for _, m := range r.members {
+		s.Participants = append(s.Participants, m.info)
+	}
+	if r.result != nil {
+		res := *r.result
+		res.Revisions = slices.Clone(res.Revisions)
+		s.Result = &res
+	}
+	return s
+}
+
+// broadcast sends the latest state to every member; callers hold the lock.
+// Each member only needs the latest snapshot, so one it hasn't read yet is replaced rather than queued.
+func (r *room) broadcast(event string) {
+	s := r.snapshot(event)
+	for _, m := range r.members {
+		select {
+		case m.updates <- s:
+		default:
+			select {
+			case <-m.updates:
+			default:
+			}
+			m.updates <- s
+		}
+	}
+}
+
+// Code is the code others use to join
+func (m *Member) Code() string {
+	return m.room.code
+}
+
+// ID identifies the member among the participants
+func (m *Member) ID() int {
+	return m.info.ID
+}
+
+// Updates delivers a snapshot whenever the session changes; it is closed when the member leaves
+func (m *Member) Updates() <-chan Snapshot {
+	return m.updates
+}
+
+// Snapshot returns the session's current state
+func (m *Member) Snapshot() Snapshot {
+	m.room.mu.Lock()
+	defer m.room.mu.Unlock()
+	return m.room.snapshot("")
+}
+
+// Edit sets a field to value. base is the field version the edit was made on; if someone else
+// has changed the field since, the edit is refused with ErrConflict.
+func (m *Member) Edit(f Field, value string, base int) (int, error) {
+	r := m.room
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.turn != m.info.ID {
+		return 0, ErrNotYourTurn
+	}
+	t := &r.fields[f]
+	if t.Version != base {
+		return 0, ErrConflict
+	}
+	t.Value = value
+	t.Version++
+	t.Author = m.info.Name
+	m.info.LastEdit = time.Now()
+	r.broadcast("")
+	return t.Version, nil
+}
+
+// SetResult shares the generated prompt, like Edit does for fields
+func (m *Member) SetResult(res Result, base int) (int, error) {
+	r := m.room
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.turn != m.info.ID {
+		return 0, ErrNotYourTurn
+	}
+	if r.resultVersion != base {
+		return 0, ErrConflict
+	}
+	res.Revisions = slices.Clone(res.Revisions)
+	r.result = &res
+	r.resultVersion++
+	m.info.LastEdit = time.Now()
+	r.broadcast("")
+	return r.resultVersion, nil
+}
+
+// SetView records the screen the member is on
+func (m *Member) SetView(view string) {
+	r := m.room
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if m.info.View == view {
+		return
+	}
+	m.info.View = view
+	r.broadcast("")
+}
+
+// Turn passes the turn on when the member has it. Otherwise the member takes it if the
+// editor has been idle for a while, or asks for it.
+func (m *Member) Turn() {
+	r := m.room
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !slices.Contains(r.members, m) {
+		return
+	}
+
+	if r.turn == m.info.ID {
+		if next := r.nextMember(m); next != nil {
+			r.give(next)
+			r.broadcast(m.info.Name + " passed the turn to " + next.info.Name)
+		}
+		return
+	}
+
+	holder := r.member(r.turn)
+	if holder == nil || time.Since(holder.info.LastEdit) >= turnIdle {
+		r.give(m)
+		r.broadcast(m.info.Name + " took the turn")
+		return
+	}
+	m.info.WantsTurn = true
+	r.broadcast(m.info.Name + " wants to edit")
+}
+
+// Leave removes the member from the session and closes its updates
+func (m *Member) Leave() {
+	r := m.room
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := slices.Index(r.members, m)
+	if i < 0 {
+		return
+	}
+	next := r.nextMember(m)
+	r.members = slices.Delete(r.members, i, i+1)
+	close(m.updates)
+
+	if len(r.members) == 0 {
+		r.closed = true
+		r.hub.remove(r.code)
+		return
+	}
+	if r.turn == m.info.ID {
+		r.give(next)
+	}
+	r.broadcast(m.info.Name + " left")
+}
+
+// give hands the turn to m; callers hold the lock
+func (r *room) give(m *Member) {
+	r.turn = m.info.ID
+	m.info.WantsTurn = false
+}
+
+// member returns the member with id; callers hold the lock
+func (r *room) member(id int) *Member {
+	for _, m := range r.members {
+		if m.info.ID == id {
+			return m
+		}
+	}
+	return nil
+}
+
+// nextMember returns who gets the turn after m, preferring someone who asked for it; callers hold the lock
+func (r *room) nextMember(m *Member) *Member {
+	for _, o := range r.members {
+		if o != m && o.info.WantsTurn {
+			return o
+		}
+	}
+	i := slices.Index(r.members, m)
+	for j := 1; j < len(r.members); j++ {
+		if o := r.members[(i+j)%len(r.members)]; o != m {
+			return o
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package collab
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// pair starts a session with alice, who has the turn, and bob
+func pair(t *testing.T) (*Hub, *Member, *Member) {
+	t.Helper()
+	h := NewHub()
+	alice, err := h.Create("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := h.Join(alice.Code(), "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h, alice, bob
+}
+
+func TestEdit(t *testing.T) {
+	_, alice, bob := pair(t)
+
+	if _, err := bob.Edit(FieldTask, "add a cache", 0); !errors.Is(err, ErrNotYourTurn) {
+		t.Errorf("Edit() without the turn = %v, want ErrNotYourTurn", err)
+	}
+	v, err := alice.Edit(FieldTask, "add a cache", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 1 {
+		t.Errorf("version = %d, want 1", v)
+	}
+	if _, err := alice.Edit(FieldTask, "add an LRU cache", 0); !errors.Is(err, ErrConflict) {
+		t.Errorf("Edit() on a stale version = %v, want ErrConflict", err)
+	}
+	if _, err := alice.SetResult(Result{Tip: "tip"}, 1); !errors.Is(err, ErrConflict) {
+		t.Errorf("SetResult() on a stale version = %v, want ErrConflict", err)
+	}
+	if _, err := bob.SetResult(Result{Tip: "tip"}, 0); !errors.Is(err, ErrNotYourTurn) {
+		t.Errorf("SetResult() without the turn = %v, want ErrNotYourTurn", err)
+	}
+
+	got := bob.Snapshot().Fields[FieldTask]
+	if got.Value != "add a cache" || got.Version != 1 || got.Author != "alice" {
+		t.Errorf("task = %+v, want alice's first edit", got)
+	}
+}
+
+func TestTurnAfterIdle(t *testing.T) {
+	_, alice, bob := pair(t)
+	if _, err := alice.Edit(FieldTask, "add a cache", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// alice just edited, so bob only asks for the turn
+	bob.Turn()
+	s := bob.Snapshot()
+	if s.Turn != alice.ID() {
+		t.Fatalf("turn = %d, want alice's %d while she's editing", s.Turn, alice.ID())
+	}
+	if !s.Participants[1].WantsTurn {
+		t.Error("bob doesn't want the turn after asking for it")
+	}
+
+	alice.room.mu.Lock()
+	alice.info.LastEdit = time.Now().Add(-turnIdle)
+	alice.room.mu.Unlock()
+
+	bob.Turn()
+	s = bob.Snapshot()
+	if s.Turn != bob.ID() {
+		t.Errorf("turn = %d, want bob's %d once alice is idle", s.Turn, bob.ID())
+	}
+	if s.Participants[1].WantsTurn {
+		t.Error("bob still wants the turn after taking it")
+	}
+}
+
+func TestPassTurnToWhoAsked(t *testing.T) {
+	h, alice, bob := pair(t)
+	carol, err := h.Join(alice.Code(), "carol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := alice.Edit(FieldTask, "add a cache", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// bob is next in join order, but carol asked
+	carol.Turn()
+	alice.Turn()
+	if turn := alice.Snapshot().Turn; turn != carol.ID() {
+		t.Errorf("turn = %d, want carol's %d", turn, carol.ID())
+	}
+
+	// Without anyone asking, the turn goes round in join order
+	carol.Turn()
+	if turn := alice.Snapshot().Turn; turn != alice.ID() {
+		t.Errorf("turn = %d, want alice's %d", turn, alice.ID())
+	}
+
+	// The holder leaving passes the turn on too
+	bob.Turn()
+	alice.Leave()
+	if turn := bob.Snapshot().Turn; turn != bob.ID() {
+		t.Errorf("turn = %d after alice left, want bob's %d", turn, bob.ID())
+	}
+}
+
+func TestLeave(t *testing.T) {
+	h, alice, bob := pair(t)
+	code := alice.Code()
+
+	alice.Leave()
+	if _, ok := <-alice.Updates(); ok {
+		// Drain the snapshot sent before leaving; the channel must then be closed
+		if _, ok := <-alice.Updates(); ok {
+			t.Error("updates still open after Leave()")
+		}
+	}
+	if !h.Exists(code) {
+		t.Fatal("session closed while bob is still in it")
+	}
+
+	bob.Leave()
+	if h.Exists(code) {
+		t.Error("session still exists after everyone left")
+	}
+	if _, err := h.Join(code, "carol"); !errors.Is(err, ErrNoRoom) {
+		t.Errorf("Join() after everyone left = %v, want ErrNoRoom", err)
+	}
+}
@@ -3,17 +3,109 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Anthropic AnthropicConfig `yaml:"anthropic"`
+	Anthropic  AnthropicConfig  `yaml:"anthropic"`
+	OpenAI     OpenAIConfig     `yaml:"openai"`
+	SecretWord SecretWordConfig `yaml:"secret_word"`
+	Privacy    PrivacyConfig    `yaml:"privacy"`
+
+	Experiments []ExperimentConfig `yaml:"experiments"` // System prompt A/B tests, outcomes go to ~/.promptgo/experiments.jsonl
+	Workspaces  []WorkspaceConfig  `yaml:"workspaces"`  // Teams sharing templates, house rules and a prompt library
+	Admin       AdminConfig        `yaml:"admin"`
 }
 
 type AnthropicConfig struct {
 	APIKey string `yaml:"api_key"`
 	Model  string `yaml:"model"` // "claude-3-5-haiku-20241022" or "claude-3-5-sonnet-20241022"
+
+	MaxTokens   int64 `yaml:"max_tokens"`   // Response limit per call, 0 for the default
+	InputBudget int   `yaml:"input_budget"` // Input tokens per request before context is trimmed, 0 for the default
+
+	Routing RoutingConfig `yaml:"routing"`
+}
+
+// OpenAIConfig adds OpenAI, or another API compatible with its chat completions, as a second
+// provider. Routing and fallbacks name its models "openai:MODEL", e.g. "openai:gpt-4o".
+type OpenAIConfig struct {
+	APIKey  string `yaml:"api_key"`
+	BaseURL string `yaml:"base_url"` // Empty for the OpenAI API
+}
+
+// RoutingConfig picks models per operation; empty fields use Model
+type RoutingConfig struct {
+	Analysis   string        `yaml:"analysis"`   // Task classification and questions, suits a fast model
+	Generation string        `yaml:"generation"` // Prompt generation, suits a strong model
+	Refinement string        `yaml:"refinement"` // Empty uses the generation model
+	Critique   string        `yaml:"critique"`   // Optional review pass after generation
+	Rules      []RoutingRule `yaml:"rules"`      // Checked before the settings above, first match wins
+	Fallbacks  []string      `yaml:"fallbacks"`  // Models to try when the routed one is overloaded or failing, on any provider
+}
+
+// RoutingRule routes an operation to a model by task type or input size
+type RoutingRule struct {
+	Operation      string `yaml:"operation"`
+	TaskType       string `yaml:"task_type"`
+	MinInputTokens int    `yaml:"min_input_tokens"`
+	Model          string `yaml:"model"`
+}
+
+// SecretWordConfig controls generated secret words
+type SecretWordConfig struct {
+	Adjectives     []string `yaml:"adjectives"` // Empty uses the built-in list
+	Nouns          []string `yaml:"nouns"`      // Empty uses the built-in list
+	AvoidCodeWords bool     `yaml:"avoid_code_words"`
+}
+
+// PrivacyConfig is the policy for sensitive data found in user input
+type PrivacyConfig struct {
+	BlockSecrets bool `yaml:"block_secrets"` // Require credentials to be redacted before anything is sent
+}
+
+// AdminConfig controls operator access and per-user limits
+type AdminConfig struct {
+	Keys       []string `yaml:"keys"`        // SSH key fingerprints ("SHA256:...") allowed to run `ssh HOST admin`
+	TokenQuota int64    `yaml:"token_quota"` // Tokens each user may spend until an operator resets them, 0 for no limit
+}
+
+// ExperimentConfig splits users between system prompt versions
+type ExperimentConfig struct {
+	ID       string          `yaml:"id"`
+	Variants []VariantConfig `yaml:"variants"`
+}
+
+// VariantConfig is one arm of an experiment; empty versions use the defaults
+type VariantConfig struct {
+	Name      string `yaml:"name"`
+	Percent   int    `yaml:"percent"`   // Share of users, the variants' total should be at most 100
+	Analyzer  string `yaml:"analyzer"`  // Analyzer prompt version, e.g. "analyzer-v1"
+	Generator string `yaml:"generator"` // Generator prompt version, e.g. "generator-v2"
+}
+
+// WorkspaceConfig is a team workspace and its members
+type WorkspaceConfig struct {
+	ID      string   `yaml:"id"`
+	Name    string   `yaml:"name"`
+	Members []string `yaml:"members"` // SSH key fingerprints ("SHA256:...") or "*" for everyone
+
+	HouseRules string           `yaml:"house_rules"` // Appended to every prompt generated in the workspace
+	Templates  []TemplateConfig `yaml:"templates"`
+
+	// Override the anthropic settings for the workspace; empty or zero keeps them. Routing still applies.
+	Model       string `yaml:"model"`
+	MaxTokens   int64  `yaml:"max_tokens"`
+	InputBudget int    `yaml:"input_budget"`
+}
+
+// TemplateConfig prefills the task and details
+type TemplateConfig struct {
+	Name    string `yaml:"name"`
+	Task    string `yaml:"task"`
+	Details string `yaml:"details"`
 }
 
 // Load loads configuration from ~/.promptgo/config.yaml or environment variables
@@ -31,14 +123,18 @@ func Load() (*Config, error) {
 		if os.IsNotExist(err) {
 			return &Config{
 				Anthropic: AnthropicConfig{
-					Model: "claude-3-5-haiku-20241022",
+					APIKey: os.Getenv("ANTHROPIC_API_KEY"),
+					Model:  "claude-3-5-haiku-20241022",
 				},
+				OpenAI:     OpenAIConfig{APIKey: os.Getenv("OPENAI_API_KEY")},
+				SecretWord: SecretWordConfig{AvoidCodeWords: true},
 			}, nil
 		}
 		return nil, err
 	}
 
-	var cfg Config
+	// Settings missing from the file keep these defaults
+	cfg := Config{SecretWord: SecretWordConfig{AvoidCodeWords: true}}
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, err
 	}
@@ -47,6 +143,9 @@ func Load() (*Config, error) {
 	if cfg.Anthropic.APIKey == "" {
 		cfg.Anthropic.APIKey = os.Getenv("ANTHROPIC_API_KEY")
 	}
+	if cfg.OpenAI.APIKey == "" {
+		cfg.OpenAI.APIKey = os.Getenv("OPENAI_API_KEY")
+	}
 
 	// Set default model if not specified
 	if cfg.Anthropic.Model == "" {
@@ -55,3 +154,100 @@ func Load() (*Config, error) {
 
 	return &cfg, nil
 }
+
+// lastSecretWordPath is where local mode remembers the last secret word used
+func lastSecretWordPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".promptgo", "last_secret_word"), nil
+}
+
+// LastSecretWord returns the secret word saved by SaveLastSecretWord, or "" if there is none
+func LastSecretWord() string {
+	path, err := lastSecretWordPath()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// SaveLastSecretWord remembers the secret word to prefill next time
+func SaveLastSecretWord(word string) error {
+	path, err := lastSecretWordPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(word+"\n"), 0600)
+}
+
+// ExperimentLogPath is where experiment outcomes are recorded
+func ExperimentLogPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".promptgo", "experiments.jsonl"), nil
+}
+
+// FeedbackLogPath is where ratings and comments on generated prompts are recorded
+func FeedbackLogPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".promptgo", "feedback.jsonl"), nil
+}
+
+// LibraryPath is where prompts published to workspace libraries are kept
+func LibraryPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".promptgo", "library.jsonl"), nil
+}
+
+// SharesPath is where shared prompts are kept
+func SharesPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".promptgo", "shares.json"), nil
+}
+
+// DraftsPath is where each user's autosaved draft is kept
+func DraftsPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".promptgo", "drafts.json"), nil
+}
+
+// HistoryPath is where each user's generated prompts are kept
+func HistoryPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".promptgo", "history.json"), nil
+}
+
+// SecretWordsPath is where the server remembers each user's last secret word
+func SecretWordsPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".promptgo", "secret_words.json"), nil
+}
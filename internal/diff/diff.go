@@ -0,0 +1,114 @@
+package diff
+
+import (
+	"strings"
+	"unicode"
+)
+
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+// Edit is a run of text that is unchanged, inserted or deleted
+type Edit struct {
+	Op   Op
+	Text string
+}
+
+// Lines computes a line-level diff between a and b
+func Lines(a, b string) []Edit {
+	return compute(splitLines(a), splitLines(b))
+}
+
+// Words computes a word-level diff between a and b.
+// Whitespace runs are kept as their own tokens so joining the edits reproduces the input.
+func Words(a, b string) []Edit {
+	return merge(compute(splitWords(a), splitWords(b)))
+}
+
+// compute returns the shortest edit script turning a into b using a longest common subsequence table
+func compute(a, b []string) []Edit {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var edits []Edit
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			edits = append(edits, Edit{Op: Equal, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, Edit{Op: Delete, Text: a[i]})
+			i++
+		default:
+			edits = append(edits, Edit{Op: Insert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		edits = append(edits, Edit{Op: Delete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		edits = append(edits, Edit{Op: Insert, Text: b[j]})
+	}
+
+	return edits
+}
+
+// merge joins adjacent edits with the same op
+func merge(edits []Edit) []Edit {
+	var merged []Edit
+	for _, e := range edits {
+		if n := len(merged); n > 0 && merged[n-1].Op == e.Op {
+			merged[n-1].Text += e.Text
+			continue
+		}
+		merged = append(merged, e)
+	}
+	return merged
+}
+
+// splitLines splits text into lines without their trailing newline
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// splitWords splits text into alternating runs of whitespace and non-whitespace
+func splitWords(s string) []string {
+	var tokens []string
+	start := 0
+	inSpace := false
+	for i, r := range s {
+		space := unicode.IsSpace(r)
+		if i > start && space != inSpace {
+			tokens = append(tokens, s[start:i])
+			start = i
+		}
+		inSpace = space
+	}
+	if start < len(s) {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
@@ -0,0 +1,95 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Edit
+	}{
+		{
+			name: "identical",
+			a:    "one\ntwo\n",
+			b:    "one\ntwo",
+			want: []Edit{{Equal, "one"}, {Equal, "two"}},
+		},
+		{
+			name: "changed line",
+			a:    "one\ntwo\nthree",
+			b:    "one\n2\nthree",
+			want: []Edit{{Equal, "one"}, {Delete, "two"}, {Insert, "2"}, {Equal, "three"}},
+		},
+		{
+			name: "appended",
+			a:    "one",
+			b:    "one\ntwo",
+			want: []Edit{{Equal, "one"}, {Insert, "two"}},
+		},
+		{
+			name: "from empty",
+			a:    "",
+			b:    "one",
+			want: []Edit{{Insert, "one"}},
+		},
+		{
+			name: "both empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Edit
+	}{
+		{
+			name: "changed word",
+			a:    "use a map cache",
+			b:    "use an LRU cache",
+			want: []Edit{{Equal, "use "}, {Delete, "a"}, {Insert, "an"}, {Equal, " "}, {Delete, "map"}, {Insert, "LRU"}, {Equal, " cache"}},
+		},
+		{
+			name: "whitespace change",
+			a:    "a b",
+			b:    "a  b",
+			want: []Edit{{Equal, "a"}, {Delete, " "}, {Insert, "  "}, {Equal, "b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Words(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Words() = %v, want %v", got, tt.want)
+			}
+
+			// Joining the kept and deleted runs gives a back, the kept and inserted ones b
+			var a, b strings.Builder
+			for _, e := range got {
+				if e.Op != Insert {
+					a.WriteString(e.Text)
+				}
+				if e.Op != Delete {
+					b.WriteString(e.Text)
+				}
+			}
+			if a.String() != tt.a || b.String() != tt.b {
+				t.Errorf("edits rebuild %q and %q, want %q and %q", a.String(), b.String(), tt.a, tt.b)
+			}
+		})
+	}
+}
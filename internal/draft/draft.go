@@ -0,0 +1,160 @@
+package draft
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"promptgo/internal/ai"
+	"promptgo/internal/enhancer"
+)
+
+// Pending is a refinement that was still running when the draft was saved
+type Pending struct {
+	Feedback string    `json:"feedback"`
+	Started  time.Time `json:"started"`
+}
+
+// Draft is a user's work in progress, saved so it survives a dropped connection
+type Draft struct {
+	Task       string `json:"task"`
+	Details    string `json:"details"`
+	SecretWord string `json:"secret_word"`
+	Focused    int    `json:"focused"`             // Focused input field
+	Stack      string `json:"stack,omitempty"`     // Chosen stack pack ID
+	Workspace  string `json:"workspace,omitempty"` // Workspace ID, empty when working alone
+
+	// Generated prompt, empty before the first generation
+	Input         *enhancer.Input   `json:"input,omitempty"` // Input the revisions were generated from
+	TaskType      ai.TaskType       `json:"task_type,omitempty"`
+	QA            map[string]string `json:"qa,omitempty"` // Answered questions
+	Revisions     []ai.Revision     `json:"revisions,omitempty"`
+	RevisionIndex int               `json:"revision_index"`
+	Tip           string            `json:"tip,omitempty"`
+	Result        bool              `json:"result"` // The result view was open
+
+	Pending *Pending `json:"pending,omitempty"`
+	Error   string   `json:"error,omitempty"` // Why the pending refinement failed
+
+	Saved time.Time `json:"saved"`
+}
+
+// Empty reports whether there is nothing worth resuming
+func (d Draft) Empty() bool {
+	return strings.TrimSpace(d.Task) == "" && strings.TrimSpace(d.Details) == "" && len(d.Revisions) == 0 && d.Pending == nil
+}
+
+// Store keeps one draft per user in a JSON file
+type Store struct {
+	mu     sync.Mutex
+	path   string // Empty keeps drafts in memory only
+	drafts map[string]Draft
+}
+
+// NewStore opens the drafts saved at path
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, drafts: make(map[string]Draft)}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read drafts: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.drafts); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	// Refinements don't survive a restart
+	for user, d := range s.drafts {
+		if d.Pending != nil {
+			d.Pending = nil
+			d.Error = "the server restarted before it finished"
+			s.drafts[user] = d
+		}
+	}
+	return s, nil
+}
+
+// Get returns user's draft
+func (s *Store) Get(user string) (Draft, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.drafts[user]
+	return d, ok
+}
+
+// Save replaces user's draft; an empty draft deletes it. Saving an unchanged draft doesn't touch the file.
+func (s *Store) Save(user string, d Draft) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.drafts[user]
+	if d.Empty() {
+		if !ok {
+			return nil
+		}
+		delete(s.drafts, user)
+		return s.save()
+	}
+	d.Saved = old.Saved
+	if ok && reflect.DeepEqual(old, d) {
+		return nil
+	}
+	d.Saved = time.Now().UTC()
+	s.drafts[user] = d
+	return s.save()
+}
+
+// Update changes user's draft in place; it does nothing when user has none
+func (s *Store) Update(user string, fn func(*Draft)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.drafts[user]
+	if !ok {
+		return nil
+	}
+	fn(&d)
+	d.Saved = time.Now().UTC()
+	s.drafts[user] = d
+	return s.save()
+}
+
+// Delete discards user's draft
+func (s *Store) Delete(user string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.drafts[user]; !ok {
+		return nil
+	}
+	delete(s.drafts, user)
+	return s.save()
+}
+
+// save writes every draft to the file; callers hold the lock
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.drafts, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	// Write then rename so a crash can't leave a truncated file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
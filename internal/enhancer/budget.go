@@ -0,0 +1,153 @@
+package enhancer
+
+import (
+	"fmt"
+	"strings"
+
+	"promptgo/internal/ai"
+)
+
+const (
+	// DefaultInputBudget bounds the tokens sent per request when no budget is configured
+	DefaultInputBudget = 30000
+
+	promptOverhead = 1000 // Reserved for system prompts, stack guidance and framing
+	minSection     = 50   // Sections that would be cut below this many tokens are dropped instead
+	trimmedNote    = "\n[... trimmed to fit the token budget]"
+)
+
+// SectionUsage is how many tokens one part of the input uses after trimming
+type SectionUsage struct {
+	Name     string
+	Tokens   int
+	Original int // Tokens before trimming
+}
+
+// Trimmed reports whether the section was cut to fit
+func (s SectionUsage) Trimmed() bool {
+	return s.Tokens < s.Original
+}
+
+// Budget is the planned token usage of a request
+type Budget struct {
+	Sections []SectionUsage
+	Total    int // Including the reserved overhead
+	Limit    int
+}
+
+// String summarizes the budget for display, e.g. "task 120 · details 300 · project 2.0k (condensed) · 3.4k/30k"
+func (b Budget) String() string {
+	var parts []string
+	for _, s := range b.Sections {
+		if s.Original == 0 {
+			continue
+		}
+		part := s.Name + " " + formatTokens(s.Tokens)
+		if s.Trimmed() {
+			part += " (condensed)"
+		}
+		parts = append(parts, part)
+	}
+	parts = append(parts, formatTokens(b.Total)+"/"+formatTokens(b.Limit))
+	return strings.Join(parts, " · ")
+}
+
+// Trimmed reports whether any section was cut to fit
+func (b Budget) Trimmed() bool {
+	for _, s := range b.Sections {
+		if s.Trimmed() {
+			return true
+		}
+	}
+	return false
+}
+
+// section returns the input text a budget section covers, nil for sections that are always kept
+func (input *Input) section(name string) *string {
+	switch name {
+	case "details":
+		return &input.Details
+	case "changes":
+		return &input.ChangeContext
+	case "API map":
+		return &input.APIMap
+	case "project":
+		return &input.ProjectContext
+	}
+	return nil
+}
+
+// estimateInput approximates the tokens of an untrimmed input, which routing rules compare against
+func estimateInput(input Input, qa map[string]string, model string) int {
+	tokens := 0
+	for _, text := range []string{input.Task, input.Details, input.ChangeContext, input.APIMap, input.ProjectContext} {
+		tokens += ai.EstimateTokens(model, text)
+	}
+	for q, a := range qa {
+		tokens += ai.EstimateTokens(model, q+a)
+	}
+	return tokens + promptOverhead
+}
+
+// PlanBudget fits an input into limit tokens for model. The task and Q&A are always kept;
+// details, then change context, the API map and the project summary are trimmed in turn.
+func PlanBudget(input Input, qa map[string]string, model string, limit int) (Input, Budget) {
+	if limit <= 0 {
+		limit = DefaultInputBudget
+	}
+	b := Budget{Limit: limit}
+	remaining := limit - promptOverhead
+
+	keep := func(name, text string) {
+		tokens := ai.EstimateTokens(model, text)
+		b.Sections = append(b.Sections, SectionUsage{Name: name, Tokens: tokens, Original: tokens})
+		remaining -= tokens
+	}
+	fit := func(name string, text *string) {
+		original := ai.EstimateTokens(model, *text)
+		if original > remaining {
+			*text = truncateTokens(*text, remaining, model)
+		}
+		tokens := ai.EstimateTokens(model, *text)
+		b.Sections = append(b.Sections, SectionUsage{Name: name, Tokens: tokens, Original: original})
+		remaining -= tokens
+	}
+
+	keep("task", input.Task)
+	var qaText strings.Builder
+	for q, a := range qa {
+		qaText.WriteString(q + a)
+	}
+	keep("Q&A", qaText.String())
+	fit("details", &input.Details)
+	fit("changes", &input.ChangeContext)
+	fit("API map", &input.APIMap)
+	fit("project", &input.ProjectContext)
+
+	b.Total = limit - remaining
+	return input, b
+}
+
+// truncateTokens cuts text to roughly tokens tokens, or drops it when too little room is left
+func truncateTokens(text string, tokens int, model string) string {
+	if tokens < minSection {
+		return ""
+	}
+	chars := int(float64(tokens)*ai.CharsPerToken(model)) - len(trimmedNote)
+	if chars <= 0 || chars >= len(text) {
+		return text
+	}
+	// Prefer to cut at a line break so code and lists stay intact
+	cut := text[:chars]
+	if i := strings.LastIndexByte(cut, '\n'); i > chars/2 {
+		cut = cut[:i]
+	}
+	return strings.ToValidUTF8(cut, "") + trimmedNote
+}
+
+func formatTokens(n int) string {
+	if n >= 1000 {
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	}
+	return fmt.Sprint(n)
+}
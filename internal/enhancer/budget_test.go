@@ -0,0 +1,162 @@
+package enhancer
+
+import (
+	"strings"
+	"testing"
+)
+
+// lines returns n lines of 39 characters plus a newline, 10 tokens each for gpt models
+func lines(n int) string {
+	return strings.Repeat(strings.Repeat("x", 39)+"\n", n)
+}
+
+func TestPlanBudget(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   Input
+		limit   int
+		trimmed []string // Sections cut to fit
+		dropped []string // Sections removed entirely
+	}{
+		{
+			name:  "fits",
+			input: Input{Task: "add a cache", Details: lines(10), ProjectContext: lines(10)},
+			limit: 2000,
+		},
+		{
+			name:    "details trimmed, later sections dropped",
+			input:   Input{Task: "add a cache", Details: lines(200), ProjectContext: lines(10)},
+			limit:   2000,
+			trimmed: []string{"details", "project"},
+			dropped: []string{"project"},
+		},
+		{
+			name:    "project trimmed last",
+			input:   Input{Task: "add a cache", Details: lines(10), ChangeContext: lines(10), ProjectContext: lines(200)},
+			limit:   2000,
+			trimmed: []string{"project"},
+		},
+		{
+			name:    "too little room drops a section",
+			input:   Input{Task: "add a cache", Details: lines(10)},
+			limit:   1040,
+			trimmed: []string{"details"},
+			dropped: []string{"details"},
+		},
+		{
+			name:  "default limit",
+			input: Input{Task: "add a cache", ProjectContext: lines(2000)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, b := PlanBudget(tt.input, nil, "gpt-4o", tt.limit)
+
+			limit := tt.limit
+			if limit == 0 {
+				limit = DefaultInputBudget
+			}
+			if b.Limit != limit {
+				t.Errorf("Limit = %d, want %d", b.Limit, limit)
+			}
+			if b.Total > limit {
+				t.Errorf("Total = %d, over the limit %d", b.Total, limit)
+			}
+			if got.Task != tt.input.Task {
+				t.Errorf("Task = %q, the task must always be kept", got.Task)
+			}
+
+			var trimmed []string
+			for _, s := range b.Sections {
+				if s.Trimmed() {
+					trimmed = append(trimmed, s.Name)
+				}
+			}
+			if strings.Join(trimmed, ",") != strings.Join(tt.trimmed, ",") {
+				t.Errorf("trimmed sections = %v, want %v", trimmed, tt.trimmed)
+			}
+			if b.Trimmed() != (len(tt.trimmed) > 0) {
+				t.Errorf("Trimmed() = %v", b.Trimmed())
+			}
+
+			for _, name := range tt.trimmed {
+				text := *got.section(name)
+				switch {
+				case contains(tt.dropped, name):
+					if text != "" {
+						t.Errorf("%s = %d chars, want it dropped", name, len(text))
+					}
+				case !strings.HasSuffix(text, trimmedNote):
+					t.Errorf("%s doesn't end with the trimmed note", name)
+				}
+			}
+		})
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestTruncateTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		tokens int
+		want   string
+	}{
+		{
+			name:   "short text kept",
+			text:   "add a cache",
+			tokens: 100,
+			want:   "add a cache",
+		},
+		{
+			name:   "below the minimum section size",
+			text:   lines(100),
+			tokens: minSection - 1,
+			want:   "",
+		},
+		{
+			name:   "cut at a line break",
+			text:   lines(100),
+			tokens: 100,
+			want:   strings.TrimSuffix(lines(9), "\n") + trimmedNote,
+		},
+		{
+			name:   "no invalid UTF-8 at the cut",
+			text:   strings.Repeat("€", 1000),
+			tokens: 60,
+			want:   strings.Repeat("€", 67) + trimmedNote, // 202 bytes fit, one into the 68th rune
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateTokens(tt.text, tt.tokens, "gpt-4o"); got != tt.want {
+				t.Errorf("truncateTokens() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBudgetString(t *testing.T) {
+	b := Budget{
+		Sections: []SectionUsage{
+			{Name: "task", Tokens: 120, Original: 120},
+			{Name: "details", Tokens: 0, Original: 0},
+			{Name: "project", Tokens: 2000, Original: 5000},
+		},
+		Total: 3400,
+		Limit: 30000,
+	}
+	if got, want := b.String(), "task 120 · project 2.0k (condensed) · 3.4k/30.0k"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,171 @@
+package enhancer
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"slices"
+	"sync"
+
+	"promptgo/internal/ai"
+	"promptgo/internal/templates"
+)
+
+// CandidateSpec describes how one candidate prompt is generated
+type CandidateSpec struct {
+	Label       string
+	Model       string   // Empty uses the enhancer's model
+	Temperature *float64 // Nil uses the API default
+	Template    string   // Methodology template to follow, empty for a fully custom prompt
+}
+
+// Candidate is one generated prompt along with what it cost
+type Candidate struct {
+	Spec         CandidateSpec
+	Prompt       string
+	Model        string
+	InputTokens  int64
+	OutputTokens int64
+	Cost         float64
+	CostKnown    bool
+
+	PromptVersion string
+}
+
+// CandidateResult reports the outcome of the spec at Index
+type CandidateResult struct {
+	Index     int
+	Candidate *Candidate
+	Err       error
+}
+
+// MaxCandidates bounds the candidates generated at once
+const MaxCandidates = 4
+
+// Key identifies the spec's settings, its model, temperature and template, so how often users
+// choose it can be tracked across sessions
+func (s CandidateSpec) Key() string {
+	model := s.Model
+	if model == "" {
+		model = "routed"
+	}
+	temperature := "default"
+	if s.Temperature != nil {
+		temperature = fmt.Sprintf("%.1f", *s.Temperature)
+	}
+	template := "custom"
+	if s.Template != "" {
+		template = fmt.Sprintf("%x", sha256.Sum256([]byte(s.Template)))[:8]
+	}
+	return model + "|" + temperature + "|" + template
+}
+
+// DefaultCandidateSpecs returns the candidate variations offered by default for an input: the
+// routed model focused, creative and following the methodology template, then each other model
+// generation may use. models lists those models with the routed one first, as from CandidateModels.
+func DefaultCandidateSpecs(input Input, models []string) []CandidateSpec {
+	focused, creative := 0.2, 1.0
+	specs := []CandidateSpec{
+		{Label: "Focused", Temperature: &focused},
+		{Label: "Creative", Temperature: &creative},
+		{Label: "Methodology", Template: templates.SystemPromptFor(input.stack())},
+	}
+	for _, m := range models[min(1, len(models)):] {
+		specs = append(specs, CandidateSpec{Label: ai.ModelName(m), Model: m})
+	}
+	return specs
+}
+
+// RankCandidateSpecs orders specs by rate, the estimated chance the user chooses each one given
+// its key, and keeps the best n. Ties keep their order.
+func RankCandidateSpecs(specs []CandidateSpec, rate func(key string) float64, n int) []CandidateSpec {
+	ranked := slices.Clone(specs)
+	if rate != nil {
+		slices.SortStableFunc(ranked, func(a, b CandidateSpec) int {
+			return cmp.Compare(rate(b.Key()), rate(a.Key()))
+		})
+	}
+	return ranked[:min(n, len(ranked))]
+}
+
+// GenerateCandidates generates one candidate per spec with at most limit calls in flight.
+// Results arrive on the returned channel as they finish, and it is closed once every spec has reported.
+// Cancelling ctx aborts the calls that are still waiting or running.
+func (e *Enhancer) GenerateCandidates(ctx context.Context, input Input, taskType ai.TaskType, qa map[string]string, specs []CandidateSpec, limit int) <-chan CandidateResult {
+	if limit < 1 {
+		limit = 1
+	}
+
+	// Buffered so workers never block if the reader stops listening
+	results := make(chan CandidateResult, len(specs))
+	sem := make(chan struct{}, limit)
+
+	go func() {
+		// Every candidate shares the condensed context; the first one to finish is charged for it
+		req, spent := e.promptRequest(ctx, ai.OpGeneration, input, taskType, qa)
+		var charge sync.Once
+
+		var wg sync.WaitGroup
+		for i, spec := range specs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+					results <- CandidateResult{Index: i, Err: ctx.Err()}
+					return
+				}
+
+				candidate, err := e.generateCandidate(ctx, req, input, spec)
+				if candidate != nil {
+					charge.Do(func() {
+						candidate.InputTokens += spent.input
+						candidate.OutputTokens += spent.output
+					})
+				}
+				results <- CandidateResult{Index: i, Candidate: candidate, Err: err}
+			}()
+		}
+
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// generateCandidate runs a single generation for spec
+func (e *Enhancer) generateCandidate(ctx context.Context, req ai.PromptRequest, input Input, spec CandidateSpec) (*Candidate, error) {
+	generate := func() (*ai.Generation, error) {
+		return e.aiClient.GeneratePromptWith(ctx, req, ai.GenerateOptions{
+			CallOptions: ai.CallOptions{
+				Model:       spec.Model,
+				Temperature: spec.Temperature,
+			},
+			Template: spec.Template,
+		})
+	}
+	gen, err := generate()
+	if err != nil {
+		return nil, err
+	}
+	gen = keepGate(gen, input.SecretWord, generate)
+
+	cost, known := ai.EstimateCost(gen.Model, gen.InputTokens, gen.OutputTokens)
+
+	return &Candidate{
+		Spec:         spec,
+		Prompt:       withHouseRules(gen.Prompt, input.HouseRules),
+		Model:        gen.Model,
+		InputTokens:  gen.InputTokens,
+		OutputTokens: gen.OutputTokens,
+		Cost:         cost,
+		CostKnown:    known,
+
+		PromptVersion: gen.PromptVersion,
+	}, nil
+}
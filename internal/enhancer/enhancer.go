@@ -2,81 +2,338 @@ package enhancer
 
 import (
 	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
+	"sync"
 
 	"promptgo/internal/ai"
+	"promptgo/internal/secret"
+	"promptgo/internal/templates"
 )
 
 type Input struct {
 	Task       string
 	Details    string
 	SecretWord string
+
+	ProjectContext string // Summary of the user's codebase, optional
+	APIMap         string // Go API relevant to the task, used for generation only
+	ChangeContext  string // Changed files, commits and test failures, optional
+	Stack          string // Stack pack ID, empty to detect it from the task
+
+	// System prompt versions, usually set by an experiment; empty uses the defaults
+	AnalyzerVersion  string
+	GeneratorVersion string
+
+	HouseRules string // Workspace rules appended to every generated prompt, optional
 }
 
 type Output struct {
 	EnhancedPrompt string
 	Tip            string
+	Model          string // Model that generated the prompt, empty for the offline fallback
+	InputTokens    int64
+	OutputTokens   int64
+	PromptVersion  string // Generator system prompt version
 }
 
 // QuestionsOutput represents the result of task analysis
 type QuestionsOutput struct {
-	TaskType  ai.TaskType
-	Questions []string
+	TaskType      ai.TaskType
+	Questions     []string
+	PromptVersion string // Analyzer system prompt version
+	Model         string
+	InputTokens   int64
+	OutputTokens  int64
 }
 
 type Enhancer struct {
-	aiClient *ai.Client
+	aiClient    *ai.Client
+	inputBudget int
+
+	mu        sync.Mutex
+	summaries map[summaryKey]string // Condensed context, reused by refinements and candidates
+}
+
+// summaryKey identifies a summary by the task and text it condensed and its token limit
+type summaryKey struct {
+	hash   [sha256.Size]byte
+	tokens int
+}
+
+// maxSummaries bounds the summary cache; it is emptied when full
+const maxSummaries = 64
+
+// tokens counts tokens spent outside the main call, such as on summaries
+type tokens struct {
+	input, output int64
 }
 
 // NewEnhancer creates a new enhancer with AI capabilities
 func NewEnhancer(apiKey string, model string) *Enhancer {
+	return NewEnhancerWithClient(ai.NewClient(apiKey, model))
+}
+
+// NewEnhancerWithClient creates an enhancer around an existing client, such as one using a fake provider
+func NewEnhancerWithClient(client *ai.Client) *Enhancer {
 	return &Enhancer{
-		aiClient: ai.NewClient(apiKey, model),
+		aiClient:    client,
+		inputBudget: DefaultInputBudget,
+		summaries:   make(map[summaryKey]string),
+	}
+}
+
+// SetLimits sets the response token limit and the input token budget; zero keeps the defaults
+func (e *Enhancer) SetLimits(maxTokens int64, inputBudget int) {
+	e.aiClient.SetMaxTokens(maxTokens)
+	if inputBudget <= 0 {
+		inputBudget = DefaultInputBudget
+	}
+	e.inputBudget = inputBudget
+}
+
+// SetRouting configures per-operation models and fallbacks
+func (e *Enhancer) SetRouting(r ai.Routing) {
+	e.aiClient.SetRouting(r)
+}
+
+// AddProvider makes another provider's models available to routing as "name:model"
+func (e *Enhancer) AddProvider(name string, p ai.Provider) {
+	e.aiClient.AddProvider(name, p)
+}
+
+// CandidateModels lists the models generation may use, the routed one first
+func (e *Enhancer) CandidateModels() []string {
+	return e.aiClient.Models(ai.OpGeneration)
+}
+
+// SetObserver registers a function called after every model call
+func (e *Enhancer) SetObserver(fn func(ai.CallEvent)) {
+	e.aiClient.SetObserver(fn)
+}
+
+// Budget plans how a generation's input tokens are spent without sending anything. Sections shown
+// as trimmed are summarized when the prompt is generated.
+func (e *Enhancer) Budget(input Input, taskType ai.TaskType, qa map[string]string) Budget {
+	_, b := e.plan(ai.OpGeneration, input, taskType, qa)
+	return b
+}
+
+// plan fits input to the budget of the model op is routed to, truncating what doesn't fit
+func (e *Enhancer) plan(op ai.Operation, input Input, taskType ai.TaskType, qa map[string]string) (Input, Budget) {
+	model := e.aiClient.RoutedModel(op, taskType, estimateInput(input, qa, e.aiClient.Model()))
+	return PlanBudget(input, qa, model, e.limit(model))
+}
+
+// limit is the input budget, lowered when model's context window can't hold it along with the response
+func (e *Enhancer) limit(model string) int {
+	limit := e.inputBudget
+	if window := ai.ContextWindow(model); window > 0 {
+		limit = min(limit, window-int(e.aiClient.MaxTokens()))
+	}
+	return limit
+}
+
+// condense fits input to the budget of the model op is routed to. Sections that don't fit are
+// summarized rather than cut off, and truncated after all when the summary fails.
+func (e *Enhancer) condense(ctx context.Context, op ai.Operation, input Input, taskType ai.TaskType, qa map[string]string) (Input, tokens) {
+	var spent tokens
+	_, b := e.plan(op, input, taskType, qa)
+	for _, s := range b.Sections {
+		text := input.section(s.Name)
+		if text == nil || !s.Trimmed() || s.Tokens < minSection {
+			continue
+		}
+		summary, used, err := e.summarize(ctx, input.Task, *text, s.Tokens)
+		if err != nil {
+			log.Printf("Truncating the %s instead of summarizing it: %v", s.Name, err)
+			continue
+		}
+		*text = summary
+		spent.input += used.input
+		spent.output += used.output
+	}
+
+	// Summaries that still don't fit are truncated
+	input, _ = e.plan(op, input, taskType, qa)
+	return input, spent
+}
+
+// summarize condenses text to maxTokens, reusing an earlier summary of the same text
+func (e *Enhancer) summarize(ctx context.Context, task, text string, maxTokens int) (string, tokens, error) {
+	key := summaryKey{hash: sha256.Sum256([]byte(task + "\x00" + text)), tokens: maxTokens}
+	e.mu.Lock()
+	summary, ok := e.summaries[key]
+	e.mu.Unlock()
+	if ok {
+		return summary, tokens{}, nil
+	}
+
+	resp, err := e.aiClient.Summarize(ctx, task, text, maxTokens)
+	if err != nil {
+		return "", tokens{}, err
+	}
+	if strings.TrimSpace(resp.Text) == "" {
+		return "", tokens{}, errors.New("empty summary")
+	}
+
+	e.mu.Lock()
+	if len(e.summaries) >= maxSummaries {
+		clear(e.summaries)
 	}
+	e.summaries[key] = resp.Text
+	e.mu.Unlock()
+	return resp.Text, tokens{input: resp.InputTokens, output: resp.OutputTokens}, nil
 }
 
 // GetQuestions analyzes the task and returns context questions (Step 1)
-func (e *Enhancer) GetQuestions(ctx context.Context, task, details string) (*QuestionsOutput, error) {
-	result, err := e.aiClient.AnalyzeTask(ctx, task, details)
+func (e *Enhancer) GetQuestions(ctx context.Context, input Input) (*QuestionsOutput, error) {
+	// Classifying the task only needs the gist, so the analysis truncates rather than summarizes
+	input, _ = e.plan(ai.OpAnalysis, input, "", nil)
+	result, err := e.aiClient.AnalyzeTask(ctx, ai.AnalysisRequest{
+		Task:           input.Task,
+		Details:        input.Details,
+		ProjectContext: input.ProjectContext,
+		ChangeContext:  input.ChangeContext,
+		StackGuidance:  input.stack().Guidance(),
+		PromptVersion:  input.AnalyzerVersion,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze task: %w", err)
 	}
 
 	return &QuestionsOutput{
-		TaskType:  result.TaskType,
-		Questions: result.Questions,
+		TaskType:      result.TaskType,
+		Questions:     result.Questions,
+		PromptVersion: result.PromptVersion,
+		Model:         result.Model,
+		InputTokens:   result.InputTokens,
+		OutputTokens:  result.OutputTokens,
 	}, nil
 }
 
 // GeneratePrompt generates the final enhanced prompt with user answers (Step 2)
 func (e *Enhancer) GeneratePrompt(ctx context.Context, input Input, taskType ai.TaskType, qa map[string]string) (*Output, error) {
-	prompt, err := e.aiClient.GeneratePrompt(ctx, ai.PromptRequest{
-		Task:       input.Task,
-		Details:    input.Details,
-		TaskType:   taskType,
-		QA:         qa,
-		SecretWord: input.SecretWord,
-	})
+	req, spent := e.promptRequest(ctx, ai.OpGeneration, input, taskType, qa)
+	generate := func() (*ai.Generation, error) {
+		return e.aiClient.GeneratePromptWith(ctx, req, ai.GenerateOptions{})
+	}
+	gen, err := generate()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate prompt: %w", err)
 	}
+	gen = keepGate(gen, input.SecretWord, generate)
+	gen.InputTokens += spent.input
+	gen.OutputTokens += spent.output
+
+	// An optional critique pass, kept only if it didn't break the gate. Its tokens are counted either
+	// way, and the result stays attributed to the generator version under test.
+	if e.aiClient.HasCritique() {
+		critiqued, err := e.aiClient.CritiquePrompt(ctx, req, gen.Prompt)
+		if err != nil {
+			log.Printf("Skipping critique: %v", err)
+		} else {
+			gen.InputTokens += critiqued.InputTokens
+			gen.OutputTokens += critiqued.OutputTokens
+			if secret.CheckGate(critiqued.Prompt, input.SecretWord).OK() {
+				gen.Prompt, gen.Model = critiqued.Prompt, critiqued.Model
+			}
+		}
+	}
 
 	tip := "This AI-generated prompt is tailored to your specific task and context. It will guide you through understanding, designing, and implementing your solution."
 
 	return &Output{
-		EnhancedPrompt: prompt,
+		EnhancedPrompt: withHouseRules(gen.Prompt, input.HouseRules),
 		Tip:            tip,
+		Model:          gen.Model,
+		InputTokens:    gen.InputTokens,
+		OutputTokens:   gen.OutputTokens,
+		PromptVersion:  gen.PromptVersion,
 	}, nil
 }
 
-// Enhance is a simple fallback function for backward compatibility
-// This will be removed once TUI is updated to use the new AI flow
-func Enhance(input Input) Output {
-	// Simple fallback that just formats the task
-	tip := "AI integration in progress. This is a temporary fallback."
+// Refine revises the latest prompt revision using the user's feedback
+func (e *Enhancer) Refine(ctx context.Context, input Input, taskType ai.TaskType, qa map[string]string, revisions []ai.Revision, feedback string) (*Output, error) {
+	req, spent := e.promptRequest(ctx, ai.OpRefinement, input, taskType, qa)
+	refine := func() (*ai.Generation, error) {
+		return e.aiClient.RefinePrompt(ctx, req, revisions, feedback)
+	}
+	gen, err := refine()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refine prompt: %w", err)
+	}
+	gen = keepGate(gen, input.SecretWord, refine)
+	gen.InputTokens += spent.input
+	gen.OutputTokens += spent.output
 
-	return Output{
-		EnhancedPrompt: fmt.Sprintf("Task: %s\n\nDetails: %s\n\nSecret Word: %s\n\n(AI enhancement coming soon)", input.Task, input.Details, input.SecretWord),
-		Tip:            tip,
+	return &Output{
+		EnhancedPrompt: withHouseRules(gen.Prompt, input.HouseRules),
+		Tip:            "Refined from your feedback. Use [ and ] to step between revisions.",
+		Model:          gen.Model,
+		InputTokens:    gen.InputTokens,
+		OutputTokens:   gen.OutputTokens,
+		PromptVersion:  gen.PromptVersion,
+	}, nil
+}
+
+// keepGate checks that gen kept the secret word gate. The gate only works if the model kept the
+// placeholder, so when it was dropped the prompt is regenerated once and the retry kept if it
+// has the gate. Either way the tokens of both calls are counted.
+func keepGate(gen *ai.Generation, word string, regenerate func() (*ai.Generation, error)) *ai.Generation {
+	gate := secret.CheckGate(gen.Prompt, word)
+	if gate.OK() {
+		return gen
+	}
+	log.Printf("Regenerating prompt: %s", gate.Problem())
+	retry, err := regenerate()
+	if err != nil {
+		return gen
+	}
+	if !secret.CheckGate(retry.Prompt, word).OK() {
+		gen.InputTokens += retry.InputTokens
+		gen.OutputTokens += retry.OutputTokens
+		return gen
+	}
+	retry.InputTokens += gen.InputTokens
+	retry.OutputTokens += gen.OutputTokens
+	return retry
+}
+
+// promptRequest builds the AI request for an input condensed to the token budget of op's model,
+// along with the tokens condensing it cost
+func (e *Enhancer) promptRequest(ctx context.Context, op ai.Operation, input Input, taskType ai.TaskType, qa map[string]string) (ai.PromptRequest, tokens) {
+	input, spent := e.condense(ctx, op, input, taskType, qa)
+	return ai.PromptRequest{
+		Task:           input.Task,
+		Details:        input.Details,
+		TaskType:       taskType,
+		QA:             qa,
+		SecretWord:     input.SecretWord,
+		ProjectContext: input.ProjectContext,
+		APIMap:         input.APIMap,
+		ChangeContext:  input.ChangeContext,
+		StackGuidance:  input.stack().Guidance(),
+		PromptVersion:  input.GeneratorVersion,
+	}, spent
+}
+
+// stack resolves the input's stack pack, detecting it from the task when none was chosen
+func (input Input) stack() templates.Stack {
+	if input.Stack == "" {
+		return templates.DetectStack(input.Task+" "+input.Details, nil)
+	}
+	return templates.StackByID(input.Stack)
+}
+
+// withHouseRules appends a workspace's house rules unless the prompt already contains them
+func withHouseRules(prompt, rules string) string {
+	rules = strings.TrimSpace(rules)
+	if rules == "" || strings.Contains(prompt, rules) {
+		return prompt
 	}
+	return strings.TrimRight(prompt, "\n") + "\n\n## House Rules\n\n" + rules + "\n"
 }
@@ -0,0 +1,70 @@
+package enhancer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"promptgo/internal/ai"
+)
+
+// critiqueProvider answers like the fake provider but critiques with a fixed token cost, keeping
+// or dropping the secret word gate
+type critiqueProvider struct {
+	keepGate bool
+}
+
+func (p critiqueProvider) Complete(ctx context.Context, r ai.ProviderRequest) (*ai.Response, error) {
+	if r.Operation != ai.OpCritique {
+		return ai.FakeProvider{}.Complete(ctx, r)
+	}
+	text := "# Critiqued\n\nJust write the code."
+	if p.keepGate {
+		text = "# Critiqued\n\nDo not write any code until the user says the secret word: \"otter\""
+	}
+	return &ai.Response{Text: text, Model: r.Model, InputTokens: 100, OutputTokens: 50}, nil
+}
+
+func TestGeneratePromptCountsCritique(t *testing.T) {
+	input := Input{Task: "add a cache", SecretWord: "otter"}
+	generate := func(p ai.Provider, critique bool) *Output {
+		client := ai.NewClientWithProvider(p, "writer")
+		if critique {
+			client.SetRouting(ai.Routing{Models: map[ai.Operation]string{ai.OpCritique: "critic"}})
+		}
+		out, err := NewEnhancerWithClient(client).GeneratePrompt(context.Background(), input, ai.TypeFeature, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	base := generate(critiqueProvider{}, false)
+
+	tests := []struct {
+		name      string
+		keepGate  bool
+		model     string
+		critiqued bool
+	}{
+		{name: "critique kept", keepGate: true, model: "critic", critiqued: true},
+		{name: "critique broke the gate", keepGate: false, model: "writer", critiqued: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := generate(critiqueProvider{keepGate: tt.keepGate}, true)
+			if out.InputTokens != base.InputTokens+100 || out.OutputTokens != base.OutputTokens+50 {
+				t.Errorf("tokens = %d in, %d out, want %d in, %d out", out.InputTokens, out.OutputTokens, base.InputTokens+100, base.OutputTokens+50)
+			}
+			if out.Model != tt.model {
+				t.Errorf("Model = %q, want %q", out.Model, tt.model)
+			}
+			if got := strings.HasPrefix(out.EnhancedPrompt, "# Critiqued"); got != tt.critiqued {
+				t.Errorf("critiqued prompt used = %v, want %v", got, tt.critiqued)
+			}
+			if out.PromptVersion != base.PromptVersion {
+				t.Errorf("PromptVersion = %q, want the generator's %q", out.PromptVersion, base.PromptVersion)
+			}
+		})
+	}
+}
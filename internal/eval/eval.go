@@ -0,0 +1,238 @@
+package eval
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"promptgo/internal/ai"
+	"promptgo/internal/enhancer"
+	"promptgo/internal/secret"
+)
+
+// defaultSecretWord is used for cases that don't set one
+const defaultSecretWord = "eval-otter"
+
+// Case is one task in a golden dataset
+type Case struct {
+	ID             string   `json:"id"`
+	Task           string   `json:"task"`
+	Details        string   `json:"details,omitempty"`
+	Stack          string   `json:"stack,omitempty"`
+	SecretWord     string   `json:"secret_word,omitempty"`
+	ExpectedType   string   `json:"expected_type"`
+	MustInclude    []string `json:"must_include,omitempty"`     // Case-insensitive substrings the prompt needs
+	MustNotInclude []string `json:"must_not_include,omitempty"` // Case-insensitive substrings the prompt must avoid
+	Answers        []string `json:"answers,omitempty"`          // Answers to the generated questions, in order
+}
+
+// Check is one rubric assertion and whether it held
+type Check struct {
+	Name string `json:"name"`
+	Pass bool   `json:"pass"`
+}
+
+// Result is how the enhancer did on one case
+type Result struct {
+	ID           string  `json:"id"`
+	ExpectedType string  `json:"expected_type"`
+	TaskType     string  `json:"task_type"`
+	TypeCorrect  bool    `json:"type_correct"`
+	Checks       []Check `json:"checks"`
+	Length       int     `json:"length"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	Cost         float64 `json:"cost"`
+	Err          string  `json:"error,omitempty"`
+}
+
+// Passed reports whether the case was classified correctly and passed every check
+func (r Result) Passed() bool {
+	if r.Err != "" || !r.TypeCorrect {
+		return false
+	}
+	for _, c := range r.Checks {
+		if !c.Pass {
+			return false
+		}
+	}
+	return true
+}
+
+// Summary aggregates results across a dataset
+type Summary struct {
+	Cases         int     `json:"cases"`
+	Passed        int     `json:"passed"`
+	Errors        int     `json:"errors"`
+	Accuracy      float64 `json:"accuracy"`        // Share of cases classified correctly
+	CheckPassRate float64 `json:"check_pass_rate"` // Share of rubric checks that held
+	AvgLength     int     `json:"avg_length"`
+	TotalTokens   int64   `json:"total_tokens"`
+	TotalCost     float64 `json:"total_cost"`
+}
+
+// Report is the outcome of an evaluation run, also used as the saved baseline
+type Report struct {
+	Model     string    `json:"model"`
+	CreatedAt time.Time `json:"created_at"`
+	Results   []Result  `json:"results"`
+	Summary   Summary   `json:"summary"`
+}
+
+// LoadCases reads a JSONL dataset, skipping blank lines
+func LoadCases(path string) ([]Case, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dataset: %w", err)
+	}
+	defer f.Close()
+
+	var cases []Case
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var c Case
+		if err := json.Unmarshal([]byte(text), &c); err != nil {
+			return nil, fmt.Errorf("dataset line %d: %w", line, err)
+		}
+		if c.ID == "" {
+			c.ID = fmt.Sprintf("line-%d", line)
+		}
+		cases = append(cases, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %w", err)
+	}
+	return cases, nil
+}
+
+// Run sends every case through analysis and generation, one at a time so results are reproducible
+func Run(ctx context.Context, enh *enhancer.Enhancer, model string, cases []Case) *Report {
+	report := &Report{Model: model, CreatedAt: time.Now().UTC()}
+	for _, c := range cases {
+		report.Results = append(report.Results, runCase(ctx, enh, c))
+	}
+	report.Summary = summarize(report.Results)
+	return report
+}
+
+// runCase evaluates a single case
+func runCase(ctx context.Context, enh *enhancer.Enhancer, c Case) Result {
+	r := Result{ID: c.ID, ExpectedType: c.ExpectedType}
+
+	word := c.SecretWord
+	if word == "" {
+		word = defaultSecretWord
+	}
+	input := enhancer.Input{Task: c.Task, Details: c.Details, SecretWord: word, Stack: c.Stack}
+
+	questions, err := enh.GetQuestions(ctx, input)
+	if err != nil {
+		r.Err = err.Error()
+		return r
+	}
+	r.TaskType = string(questions.TaskType)
+	r.TypeCorrect = c.ExpectedType == "" || strings.EqualFold(c.ExpectedType, r.TaskType)
+
+	qa := make(map[string]string)
+	for i, q := range questions.Questions {
+		if i < len(c.Answers) {
+			qa[q] = c.Answers[i]
+		}
+	}
+
+	out, err := enh.GeneratePrompt(ctx, input, questions.TaskType, qa)
+	if err != nil {
+		r.Err = err.Error()
+		return r
+	}
+
+	r.Length = len(out.EnhancedPrompt)
+	r.InputTokens, r.OutputTokens = out.InputTokens, out.OutputTokens
+	r.Cost, _ = ai.EstimateCost(out.Model, out.InputTokens, out.OutputTokens)
+	r.Checks = rubric(c, out.EnhancedPrompt, word)
+	return r
+}
+
+// rubric runs the case's assertions plus the secret word gate, which every prompt must keep
+func rubric(c Case, prompt, word string) []Check {
+	lower := strings.ToLower(prompt)
+	checks := []Check{{Name: "secret word gate", Pass: secret.CheckGate(prompt, word).OK()}}
+	for _, s := range c.MustInclude {
+		checks = append(checks, Check{Name: "includes " + s, Pass: strings.Contains(lower, strings.ToLower(s))})
+	}
+	for _, s := range c.MustNotInclude {
+		checks = append(checks, Check{Name: "excludes " + s, Pass: !strings.Contains(lower, strings.ToLower(s))})
+	}
+	return checks
+}
+
+// summarize aggregates results
+func summarize(results []Result) Summary {
+	s := Summary{Cases: len(results)}
+	correct, checks, passedChecks, generated, length := 0, 0, 0, 0, 0
+
+	for _, r := range results {
+		if r.Err != "" {
+			s.Errors++
+			continue
+		}
+		if r.Passed() {
+			s.Passed++
+		}
+		if r.TypeCorrect {
+			correct++
+		}
+		for _, c := range r.Checks {
+			checks++
+			if c.Pass {
+				passedChecks++
+			}
+		}
+		generated++
+		length += r.Length
+		s.TotalTokens += r.InputTokens + r.OutputTokens
+		s.TotalCost += r.Cost
+	}
+
+	if s.Cases > 0 {
+		s.Accuracy = float64(correct) / float64(s.Cases)
+	}
+	if checks > 0 {
+		s.CheckPassRate = float64(passedChecks) / float64(checks)
+	}
+	if generated > 0 {
+		s.AvgLength = length / generated
+	}
+	return s
+}
+
+// LoadReport reads a saved report, typically the baseline
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// Save writes the report as JSON so it can serve as a baseline
+func (r *Report) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,195 @@
+package eval
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"promptgo/internal/ai"
+	"promptgo/internal/enhancer"
+)
+
+func TestRubric(t *testing.T) {
+	prompt := "Add an LRU cache.\n\nDo not write code until I say the secret word otter."
+	tests := []struct {
+		name string
+		c    Case
+		word string
+		want []Check
+	}{
+		{
+			name: "gate only",
+			word: "otter",
+			want: []Check{{Name: "secret word gate", Pass: true}},
+		},
+		{
+			name: "includes and excludes ignore case",
+			c:    Case{MustInclude: []string{"lru", "TTL"}, MustNotInclude: []string{"redis", "CACHE"}},
+			word: "otter",
+			want: []Check{
+				{Name: "secret word gate", Pass: true},
+				{Name: "includes lru", Pass: true},
+				{Name: "includes TTL", Pass: false},
+				{Name: "excludes redis", Pass: true},
+				{Name: "excludes CACHE", Pass: false},
+			},
+		},
+		{
+			name: "wrong secret word",
+			word: "badger",
+			want: []Check{{Name: "secret word gate", Pass: false}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rubric(tt.c, prompt, tt.word); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rubric() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	results := []Result{
+		{ID: "a", TypeCorrect: true, Checks: []Check{{Pass: true}, {Pass: true}}, Length: 100, InputTokens: 10, OutputTokens: 5, Cost: 0.5},
+		{ID: "b", TypeCorrect: true, Checks: []Check{{Pass: true}, {Pass: false}}, Length: 300, InputTokens: 20, OutputTokens: 5, Cost: 0.25},
+		{ID: "c", TypeCorrect: false, Checks: []Check{{Pass: true}}, Length: 200},
+		{ID: "d", Err: "timeout"},
+	}
+	want := Summary{
+		Cases:         4,
+		Passed:        1,
+		Errors:        1,
+		Accuracy:      0.5,
+		CheckPassRate: 0.8,
+		AvgLength:     200,
+		TotalTokens:   40,
+		TotalCost:     0.75,
+	}
+	if got := summarize(results); got != want {
+		t.Errorf("summarize() = %+v, want %+v", got, want)
+	}
+	if got := summarize(nil); got != (Summary{}) {
+		t.Errorf("summarize(nil) = %+v, want zero", got)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	pass := Result{TypeCorrect: true, Checks: []Check{{Pass: true}}}
+	fail := Result{TypeCorrect: true, Checks: []Check{{Pass: false}}}
+	with := func(id string, r Result) Result {
+		r.ID = id
+		return r
+	}
+
+	baseline := &Report{
+		Results: []Result{with("kept", pass), with("broke", pass), with("fixed", fail), with("dropped", pass)},
+		Summary: Summary{Accuracy: 1, CheckPassRate: 0.75, AvgLength: 100, TotalCost: 1},
+	}
+	current := &Report{
+		Results: []Result{with("kept", pass), with("broke", fail), with("fixed", pass), with("added", pass)},
+		Summary: Summary{Accuracy: 1, CheckPassRate: 0.75, AvgLength: 120, TotalCost: 1.5},
+	}
+
+	got := Compare(baseline, current)
+	want := Comparison{AvgLengthDelta: 20, CostDelta: 0.5, Regressions: []string{"broke"}, Fixed: []string{"fixed"}, New: []string{"added"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Compare() = %+v, want %+v", got, want)
+	}
+	if !got.Regressed() {
+		t.Error("Regressed() = false with a regressed case")
+	}
+	if (Comparison{AccuracyDelta: 0.1, Fixed: []string{"x"}}).Regressed() {
+		t.Error("Regressed() = true for an improvement")
+	}
+	if !(Comparison{CheckPassRateDelta: -0.01}).Regressed() {
+		t.Error("Regressed() = false with a lower check pass rate")
+	}
+}
+
+func TestLoadCases(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantIDs []string
+		wantErr string
+	}{
+		{
+			name:    "ids default to the line",
+			data:    "{\"id\":\"cache\",\"task\":\"add a cache\"}\n\n{\"task\":\"fix login\"}\n",
+			wantIDs: []string{"cache", "line-3"},
+		},
+		{
+			name:    "bad line",
+			data:    "{\"task\":\"ok\"}\n{not json}\n",
+			wantErr: "dataset line 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cases.jsonl")
+			if err := os.WriteFile(path, []byte(tt.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cases, err := LoadCases(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadCases() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, c := range cases {
+				ids = append(ids, c.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("IDs = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestGoldenDatasetLoads(t *testing.T) {
+	cases, err := LoadCases(filepath.Join("..", "..", "eval", "golden.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	for _, c := range cases {
+		if c.Task == "" {
+			t.Errorf("case %s has no task", c.ID)
+		}
+		if seen[c.ID] {
+			t.Errorf("case ID %s is used twice", c.ID)
+		}
+		seen[c.ID] = true
+	}
+}
+
+func TestRunOffline(t *testing.T) {
+	enh := enhancer.NewEnhancerWithClient(ai.NewClientWithProvider(ai.FakeProvider{}, "fake"))
+	cases := []Case{{ID: "cache", Task: "add an in-memory cache to the user lookup", Answers: []string{"LRU"}}}
+
+	report := Run(context.Background(), enh, "fake", cases)
+	if len(report.Results) != 1 {
+		t.Fatalf("got %d results, want 1", len(report.Results))
+	}
+	r := report.Results[0]
+	if r.Err != "" {
+		t.Fatalf("case failed: %s", r.Err)
+	}
+	if len(r.Checks) == 0 || r.Checks[0].Name != "secret word gate" || !r.Checks[0].Pass {
+		t.Errorf("checks = %+v, want the secret word gate to hold", r.Checks)
+	}
+	if report.Summary.Cases != 1 || report.Summary.Errors != 0 {
+		t.Errorf("summary = %+v", report.Summary)
+	}
+}
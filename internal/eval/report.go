@@ -0,0 +1,114 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Comparison is how a run differs from its baseline
+type Comparison struct {
+	AccuracyDelta      float64
+	CheckPassRateDelta float64
+	AvgLengthDelta     int
+	CostDelta          float64
+	Regressions        []string // Cases that passed in the baseline and fail now
+	Fixed              []string // Cases that failed in the baseline and pass now
+	New                []string // Cases missing from the baseline
+}
+
+// Regressed reports whether any case or aggregate got worse
+func (c Comparison) Regressed() bool {
+	return len(c.Regressions) > 0 || c.AccuracyDelta < 0 || c.CheckPassRateDelta < 0
+}
+
+// Compare diffs a run against a baseline case by case
+func Compare(baseline, current *Report) Comparison {
+	c := Comparison{
+		AccuracyDelta:      current.Summary.Accuracy - baseline.Summary.Accuracy,
+		CheckPassRateDelta: current.Summary.CheckPassRate - baseline.Summary.CheckPassRate,
+		AvgLengthDelta:     current.Summary.AvgLength - baseline.Summary.AvgLength,
+		CostDelta:          current.Summary.TotalCost - baseline.Summary.TotalCost,
+	}
+
+	before := make(map[string]Result, len(baseline.Results))
+	for _, r := range baseline.Results {
+		before[r.ID] = r
+	}
+	for _, r := range current.Results {
+		old, ok := before[r.ID]
+		switch {
+		case !ok:
+			c.New = append(c.New, r.ID)
+		case old.Passed() && !r.Passed():
+			c.Regressions = append(c.Regressions, r.ID)
+		case !old.Passed() && r.Passed():
+			c.Fixed = append(c.Fixed, r.ID)
+		}
+	}
+	return c
+}
+
+// WriteMarkdown writes a human-readable report, with the baseline comparison when there is one
+func WriteMarkdown(w io.Writer, r *Report, cmp *Comparison) error {
+	var b strings.Builder
+	s := r.Summary
+
+	fmt.Fprintf(&b, "# PromptGo eval report\n\n")
+	fmt.Fprintf(&b, "Model: %s  \nRun at: %s\n\n", r.Model, r.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+	b.WriteString("## Summary\n\n")
+	fmt.Fprintf(&b, "| Metric | Value |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Cases | %d (%d passed, %d errors) |\n", s.Cases, s.Passed, s.Errors)
+	fmt.Fprintf(&b, "| Classification accuracy | %.1f%% |\n", s.Accuracy*100)
+	fmt.Fprintf(&b, "| Rubric checks passed | %.1f%% |\n", s.CheckPassRate*100)
+	fmt.Fprintf(&b, "| Average prompt length | %d chars |\n", s.AvgLength)
+	fmt.Fprintf(&b, "| Tokens | %d |\n", s.TotalTokens)
+	fmt.Fprintf(&b, "| Cost | $%.4f |\n\n", s.TotalCost)
+
+	if cmp != nil {
+		b.WriteString("## Compared to baseline\n\n")
+		fmt.Fprintf(&b, "- Accuracy: %+.1f pts\n", cmp.AccuracyDelta*100)
+		fmt.Fprintf(&b, "- Rubric checks: %+.1f pts\n", cmp.CheckPassRateDelta*100)
+		fmt.Fprintf(&b, "- Average length: %+d chars\n", cmp.AvgLengthDelta)
+		fmt.Fprintf(&b, "- Cost: %+.4f USD\n", cmp.CostDelta)
+		writeIDs(&b, "Regressions", cmp.Regressions)
+		writeIDs(&b, "Fixed", cmp.Fixed)
+		writeIDs(&b, "New cases", cmp.New)
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Cases\n\n")
+	b.WriteString("| Case | Expected | Got | Failed checks | Length | Cost |\n|---|---|---|---|---|---|\n")
+	for _, res := range r.Results {
+		if res.Err != "" {
+			fmt.Fprintf(&b, "| %s | %s | error: %s | | | |\n", res.ID, res.ExpectedType, escapeCell(res.Err))
+			continue
+		}
+		var failed []string
+		for _, c := range res.Checks {
+			if !c.Pass {
+				failed = append(failed, c.Name)
+			}
+		}
+		got := res.TaskType
+		if !res.TypeCorrect {
+			got = "**" + got + "**"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %d | $%.4f |\n",
+			res.ID, res.ExpectedType, got, escapeCell(strings.Join(failed, "; ")), res.Length, res.Cost)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeIDs(b *strings.Builder, label string, ids []string) {
+	if len(ids) > 0 {
+		fmt.Fprintf(b, "- %s: %s\n", label, strings.Join(ids, ", "))
+	}
+}
+
+// escapeCell keeps a value from breaking a markdown table row
+func escapeCell(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
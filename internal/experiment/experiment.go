@@ -0,0 +1,244 @@
+package experiment
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Event is an outcome tracked against the variant a session was assigned
+type Event string
+
+const (
+	EventGenerated  Event = "generated" // A prompt was shown to the user
+	EventCopy       Event = "copy"
+	EventSave       Event = "save"
+	EventRefine     Event = "refine"
+	EventThumbsUp   Event = "thumbs_up"
+	EventThumbsDown Event = "thumbs_down"
+)
+
+// Events lists every event in the order reports show them
+var Events = []Event{EventGenerated, EventCopy, EventSave, EventRefine, EventThumbsUp, EventThumbsDown}
+
+// Variant is one arm of an experiment; empty versions use the default system prompts
+type Variant struct {
+	Name             string
+	Percent          int // Share of users, 0-100
+	AnalyzerVersion  string
+	GeneratorVersion string
+}
+
+// Experiment splits users between variants
+type Experiment struct {
+	ID       string
+	Variants []Variant
+}
+
+// Assign picks a variant for a user. The same user always gets the same variant;
+// users outside every variant's share get nil and keep the defaults.
+func (e Experiment) Assign(user string) *Variant {
+	h := fnv.New32a()
+	h.Write([]byte(e.ID + "\x00" + user))
+	bucket := int(h.Sum32() % 100)
+
+	cumulative := 0
+	for i := range e.Variants {
+		cumulative += e.Variants[i].Percent
+		if bucket < cumulative {
+			return &e.Variants[i]
+		}
+	}
+	return nil
+}
+
+// Outcome is one recorded event
+type Outcome struct {
+	Time          time.Time `json:"time"`
+	Experiment    string    `json:"experiment"`
+	Variant       string    `json:"variant"`
+	User          string    `json:"user"`
+	Event         Event     `json:"event"`
+	PromptVersion string    `json:"prompt_version,omitempty"`
+}
+
+// Tally counts one variant's events
+type Tally struct {
+	Experiment string
+	Variant    string
+	Events     map[Event]int
+}
+
+// Rate returns event per generated prompt, or 0 before anything was generated
+func (t Tally) Rate(event Event) float64 {
+	if t.Events[EventGenerated] == 0 {
+		return 0
+	}
+	return float64(t.Events[event]) / float64(t.Events[EventGenerated])
+}
+
+type tallyKey struct {
+	experiment string
+	variant    string
+}
+
+// Recorder appends outcomes to a JSONL file and keeps running counts
+type Recorder struct {
+	mu     sync.Mutex
+	path   string // Empty keeps counts in memory only
+	counts map[tallyKey]map[Event]int
+}
+
+// NewRecorder creates a recorder that appends to path, counting the outcomes already in it
+func NewRecorder(path string) (*Recorder, error) {
+	r := &Recorder{path: path, counts: make(map[tallyKey]map[Event]int)}
+	if path == "" {
+		return r, nil
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open experiment log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		var o Outcome
+		if err := json.Unmarshal(scanner.Bytes(), &o); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		r.count(o)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read experiment log: %w", err)
+	}
+	return r, nil
+}
+
+// Record counts an outcome and appends it to the log
+func (r *Recorder) Record(o Outcome) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.count(o)
+	if r.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(o)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// count adds an outcome to the running counts; callers hold the lock or own r
+func (r *Recorder) count(o Outcome) {
+	key := tallyKey{o.Experiment, o.Variant}
+	if r.counts[key] == nil {
+		r.counts[key] = make(map[Event]int)
+	}
+	r.counts[key][o.Event]++
+}
+
+// Tallies returns the counts for every variant seen, sorted by experiment and variant
+func (r *Recorder) Tallies() []Tally {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tallies := make([]Tally, 0, len(r.counts))
+	for key, events := range r.counts {
+		copied := make(map[Event]int, len(events))
+		for e, n := range events {
+			copied[e] = n
+		}
+		tallies = append(tallies, Tally{Experiment: key.experiment, Variant: key.variant, Events: copied})
+	}
+	sort.Slice(tallies, func(i, j int) bool {
+		if tallies[i].Experiment != tallies[j].Experiment {
+			return tallies[i].Experiment < tallies[j].Experiment
+		}
+		return tallies[i].Variant < tallies[j].Variant
+	})
+	return tallies
+}
+
+// assignment is the variant a session got in one experiment
+type assignment struct {
+	experiment string
+	variant    *Variant
+}
+
+// Session is one user's experiment assignments. A nil Session is valid and runs no experiments.
+type Session struct {
+	user        string
+	assignments []assignment
+	recorder    *Recorder
+}
+
+// NewSession assigns user to a variant of each experiment they fall into
+func NewSession(experiments []Experiment, user string, recorder *Recorder) *Session {
+	s := &Session{user: user, recorder: recorder}
+	for _, e := range experiments {
+		if v := e.Assign(user); v != nil {
+			s.assignments = append(s.assignments, assignment{experiment: e.ID, variant: v})
+		}
+	}
+	return s
+}
+
+// Versions returns the system prompt versions to use; when experiments overlap the first one listed wins
+func (s *Session) Versions() (analyzer, generator string) {
+	if s == nil {
+		return "", ""
+	}
+	for _, a := range s.assignments {
+		if analyzer == "" {
+			analyzer = a.variant.AnalyzerVersion
+		}
+		if generator == "" {
+			generator = a.variant.GeneratorVersion
+		}
+	}
+	return analyzer, generator
+}
+
+// Track records an event for every experiment the session is part of
+func (s *Session) Track(event Event, promptVersion string) {
+	if s == nil || s.recorder == nil {
+		return
+	}
+	for _, a := range s.assignments {
+		err := s.recorder.Record(Outcome{
+			Time:          time.Now().UTC(),
+			Experiment:    a.experiment,
+			Variant:       a.variant.Name,
+			User:          s.user,
+			Event:         event,
+			PromptVersion: promptVersion,
+		})
+		if err != nil {
+			log.Printf("Failed to record experiment outcome: %v", err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// AgentFile is a rule file format that a coding agent loads automatically
+type AgentFile struct {
+	ID   string // Short name used by the rules exec command
+	Name string // Shown in the save menu
+	dir  string // Directory relative to the project root
+	file string // File name, empty when derived from the title
+	mdc  bool   // Cursor rule with frontmatter
+}
+
+// AgentFiles lists the supported agent rule formats
+var AgentFiles = []AgentFile{
+	{ID: "agents", Name: "AGENTS.md", file: "AGENTS.md"},
+	{ID: "claude", Name: "CLAUDE.md", file: "CLAUDE.md"},
+	{ID: "cursor", Name: ".cursor/rules/*.mdc", dir: ".cursor/rules", mdc: true},
+	{ID: "copilot", Name: ".github/copilot-instructions.md", dir: ".github", file: "copilot-instructions.md"},
+}
+
+// AgentFileByID looks up an agent rule format by its short name
+func AgentFileByID(id string) (AgentFile, bool) {
+	for _, f := range AgentFiles {
+		if f.ID == id {
+			return f, true
+		}
+	}
+	return AgentFile{}, false
+}
+
+// Path returns where the rule file lives relative to the project root
+func (f AgentFile) Path(title string) string {
+	name := f.file
+	if name == "" {
+		name = slugify(title) + ".mdc"
+	}
+	return filepath.Join(f.dir, name)
+}
+
+// Shared reports whether the file may already hold other instructions, so rules are appended to it
+func (f AgentFile) Shared() bool {
+	return !f.mdc
+}
+
+// Render formats body as a rule file titled title
+func (f AgentFile) Render(title, body string) string {
+	body = strings.TrimSpace(body)
+	if f.mdc {
+		return fmt.Sprintf("---\ndescription: %s\nalwaysApply: true\n---\n\n%s\n", oneLine(title), body)
+	}
+	return fmt.Sprintf("## %s\n\n%s\n", oneLine(title), body)
+}
+
+// Write saves the rule file under root. Shared files like AGENTS.md may already hold
+// other instructions, so the new section is appended rather than replacing them.
+func (f AgentFile) Write(root, title, body string) (string, error) {
+	path := filepath.Join(root, f.Path(title))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	content := f.Render(title, body)
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if f.Shared() {
+		if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+			flags = os.O_WRONLY | os.O_APPEND
+			content = "\n" + content
+		}
+	}
+
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(content); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// conventionHeadingRe matches headings of sections worth keeping as standing rules
+var conventionHeadingRe = regexp.MustCompile(`(?i)\b(rules?|conventions?|best practices|guidelines|standards|style)\b`)
+
+// Conventions extracts the reusable sections of a generated prompt: rules, conventions
+// and best practices. The whole prompt is returned when it has no such section.
+func Conventions(prompt string) string {
+	var sections []string
+	var current []string
+	level := 0 // Level of the heading that opened the current section, 0 when outside one
+	inFence := false
+
+	flush := func() {
+		if len(current) > 0 {
+			sections = append(sections, strings.TrimSpace(strings.Join(current, "\n")))
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(prompt, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if !inFence && strings.HasPrefix(line, "#") {
+			l := len(line) - len(strings.TrimLeft(line, "#"))
+			if level > 0 && l <= level {
+				flush()
+				level = 0
+			}
+			if level == 0 && conventionHeadingRe.MatchString(line[l:]) {
+				level = l
+			}
+		}
+		if level > 0 {
+			current = append(current, line)
+		}
+	}
+	flush()
+
+	if len(sections) == 0 {
+		return strings.TrimSpace(prompt)
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// DefaultTitle names a rule file after its task, falling back to a timestamp
+func DefaultTitle(task string) string {
+	task = oneLine(task)
+	if len(task) > 60 {
+		task = strings.TrimSpace(task[:60])
+	}
+	if task == "" {
+		return fmt.Sprintf("PromptGo conventions %s", time.Now().Format("2006-01-02"))
+	}
+	return strings.ToValidUTF8(task, "")
+}
+
+var slugRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify turns a title into a file name
+func slugify(title string) string {
+	slug := strings.Trim(slugRe.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(slug) > 50 {
+		slug = strings.Trim(slug[:50], "-")
+	}
+	if slug == "" {
+		slug = "promptgo"
+	}
+	return slug
+}
+
+// oneLine collapses whitespace so a value fits on one line of frontmatter or a heading
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
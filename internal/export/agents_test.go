@@ -0,0 +1,106 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestAgentFileRender(t *testing.T) {
+	tests := []struct {
+		id    string
+		title string
+		path  string
+		want  string
+	}{
+		{"agents", "Cache rules", "AGENTS.md", "## Cache rules\n\nUse LRU.\n"},
+		{"copilot", "Cache rules", filepath.Join(".github", "copilot-instructions.md"), "## Cache rules\n\nUse LRU.\n"},
+		{
+			"cursor", "Cache\nrules!", filepath.Join(".cursor", "rules", "cache-rules.mdc"),
+			"---\ndescription: Cache rules!\nalwaysApply: true\n---\n\nUse LRU.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			f, ok := AgentFileByID(tt.id)
+			if !ok {
+				t.Fatalf("AgentFileByID(%q) not found", tt.id)
+			}
+			if got := f.Path(tt.title); got != tt.path {
+				t.Errorf("Path() = %q, want %q", got, tt.path)
+			}
+			if got := f.Render(tt.title, "\nUse LRU.\n\n"); got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAgentFileWriteAppendsToShared(t *testing.T) {
+	root := t.TempDir()
+	f, _ := AgentFileByID("agents")
+	if err := os.WriteFile(filepath.Join(root, "AGENTS.md"), []byte("# Existing\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := f.Write(root, "Cache rules", "Use LRU.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if want := "# Existing\n\n## Cache rules\n\nUse LRU.\n"; string(data) != want {
+		t.Errorf("AGENTS.md = %q, want %q", data, want)
+	}
+}
+
+func TestConventions(t *testing.T) {
+	tests := []struct {
+		name   string
+		prompt string
+		want   string
+	}{
+		{
+			name:   "no convention section keeps the prompt",
+			prompt: "# Task\n\nAdd a cache.\n",
+			want:   "# Task\n\nAdd a cache.",
+		},
+		{
+			name:   "rules section with subsections",
+			prompt: "# Task\nAdd a cache.\n## Rules\n- no globals\n### Naming\n- short names\n## Steps\n1. write it",
+			want:   "## Rules\n- no globals\n### Naming\n- short names",
+		},
+		{
+			name:   "headings inside code fences are ignored",
+			prompt: "## Conventions\n```sh\n# not a heading\n```\n## Output\ndone",
+			want:   "## Conventions\n```sh\n# not a heading\n```",
+		},
+		{
+			name:   "several sections",
+			prompt: "## Style\nuse gofmt\n## Task\nx\n## Best practices\nwrap errors",
+			want:   "## Style\nuse gofmt\n\n## Best practices\nwrap errors",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Conventions(tt.prompt); got != tt.want {
+				t.Errorf("Conventions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultTitle(t *testing.T) {
+	if got := DefaultTitle("  add\na   cache "); got != "add a cache" {
+		t.Errorf("DefaultTitle() = %q", got)
+	}
+	if got := DefaultTitle(strings.Repeat("é", 40)); len(got) > 60 || !utf8.ValidString(got) {
+		t.Errorf("DefaultTitle() = %q, want at most 60 bytes of valid UTF-8", got)
+	}
+	if got := DefaultTitle(""); !strings.HasPrefix(got, "PromptGo conventions ") {
+		t.Errorf("DefaultTitle(\"\") = %q", got)
+	}
+}
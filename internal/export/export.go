@@ -0,0 +1,222 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a generated prompt and the metadata that produced it
+type Document struct {
+	Prompt     string
+	Task       string
+	Details    string
+	TaskType   string
+	Model      string
+	SecretWord string
+	CreatedAt  time.Time
+	QA         map[string]string // Question -> Answer
+}
+
+// Exporter writes a document in one file format
+type Exporter interface {
+	Name() string      // Shown in the save dialog and accepted by ExporterByName
+	Extension() string // File extension including the dot
+	Export(w io.Writer, doc Document) error
+}
+
+// Exporters lists the available formats, plain text first since it's the default
+var Exporters = []Exporter{Text{}, Markdown{}, JSON{}, HTML{}}
+
+// ExporterByName looks up an exporter by name
+func ExporterByName(name string) (Exporter, bool) {
+	for _, e := range Exporters {
+		if strings.EqualFold(e.Name(), name) {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+// Filename suggests a file name for a document in the given format
+func Filename(doc Document, e Exporter) string {
+	return fmt.Sprintf("prompt_%d%s", doc.CreatedAt.Unix(), e.Extension())
+}
+
+// Bytes renders a document to memory
+func Bytes(doc Document, e Exporter) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := e.Export(&buf, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// qaPair is a question and answer in a stable order for serialized formats
+type qaPair struct {
+	Question string `yaml:"question" json:"question"`
+	Answer   string `yaml:"answer" json:"answer"`
+}
+
+// sortedQA orders the Q&A by question so exports are deterministic
+func (d Document) sortedQA() []qaPair {
+	pairs := make([]qaPair, 0, len(d.QA))
+	for q, a := range d.QA {
+		pairs = append(pairs, qaPair{Question: q, Answer: a})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].Question < pairs[j].Question
+	})
+	return pairs
+}
+
+// Text exports the prompt alone
+type Text struct{}
+
+func (Text) Name() string      { return "text" }
+func (Text) Extension() string { return ".txt" }
+
+func (Text) Export(w io.Writer, doc Document) error {
+	_, err := io.WriteString(w, doc.Prompt)
+	return err
+}
+
+// frontMatter is the metadata written ahead of a Markdown export
+type frontMatter struct {
+	Task       string   `yaml:"task"`
+	Details    string   `yaml:"details,omitempty"`
+	Type       string   `yaml:"type,omitempty"`
+	Model      string   `yaml:"model,omitempty"`
+	SecretWord string   `yaml:"secret_word"`
+	CreatedAt  string   `yaml:"created_at"`
+	QA         []qaPair `yaml:"qa,omitempty"`
+}
+
+// Markdown exports the prompt with YAML front matter
+type Markdown struct{}
+
+func (Markdown) Name() string      { return "markdown" }
+func (Markdown) Extension() string { return ".md" }
+
+func (Markdown) Export(w io.Writer, doc Document) error {
+	meta, err := yaml.Marshal(frontMatter{
+		Task:       doc.Task,
+		Details:    doc.Details,
+		Type:       doc.TaskType,
+		Model:      doc.Model,
+		SecretWord: doc.SecretWord,
+		CreatedAt:  doc.CreatedAt.UTC().Format(time.RFC3339),
+		QA:         doc.sortedQA(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode front matter: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "---\n%s---\n\n%s\n", meta, strings.TrimSpace(doc.Prompt))
+	return err
+}
+
+// jsonDocument is the JSON export layout
+type jsonDocument struct {
+	Task       string   `json:"task"`
+	Details    string   `json:"details,omitempty"`
+	Type       string   `json:"type,omitempty"`
+	Model      string   `json:"model,omitempty"`
+	SecretWord string   `json:"secret_word"`
+	CreatedAt  string   `json:"created_at"`
+	QA         []qaPair `json:"qa,omitempty"`
+	Prompt     string   `json:"prompt"`
+}
+
+// JSON exports the prompt and metadata as a JSON object
+type JSON struct{}
+
+func (JSON) Name() string      { return "json" }
+func (JSON) Extension() string { return ".json" }
+
+func (JSON) Export(w io.Writer, doc Document) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(jsonDocument{
+		Task:       doc.Task,
+		Details:    doc.Details,
+		Type:       doc.TaskType,
+		Model:      doc.Model,
+		SecretWord: doc.SecretWord,
+		CreatedAt:  doc.CreatedAt.UTC().Format(time.RFC3339),
+		QA:         doc.sortedQA(),
+		Prompt:     doc.Prompt,
+	})
+}
+
+// htmlMarkdown converts prompts to HTML; raw HTML in the prompt is dropped, not rendered
+var htmlMarkdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+var htmlPage = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { max-width: 48rem; margin: 2rem auto; padding: 0 1rem; font: 16px/1.6 system-ui, sans-serif; color: #222; }
+pre, code { font-family: ui-monospace, monospace; background: #f5f5f5; border-radius: 4px; }
+pre { padding: 1rem; overflow-x: auto; }
+dl.meta { display: grid; grid-template-columns: max-content 1fr; gap: .25rem 1rem; padding: 1rem; background: #f9f7ff; border-radius: 6px; }
+dl.meta dt { font-weight: 600; color: #7D56F4; }
+dl.meta dd { margin: 0; }
+</style>
+</head>
+<body>
+<dl class="meta">
+<dt>Task</dt><dd>{{.Doc.Task}}</dd>
+{{with .Doc.TaskType}}<dt>Type</dt><dd>{{.}}</dd>{{end}}
+{{with .Doc.Model}}<dt>Model</dt><dd>{{.}}</dd>{{end}}
+<dt>Secret word</dt><dd><code>{{.Doc.SecretWord}}</code></dd>
+<dt>Created</dt><dd>{{.Created}}</dd>
+{{range .QA}}<dt>{{.Question}}</dt><dd>{{.Answer}}</dd>
+{{end}}</dl>
+{{.Body}}
+</body>
+</html>
+`))
+
+// HTML exports a single self-contained page with the prompt rendered from markdown
+type HTML struct{}
+
+func (HTML) Name() string      { return "html" }
+func (HTML) Extension() string { return ".html" }
+
+func (HTML) Export(w io.Writer, doc Document) error {
+	var body bytes.Buffer
+	if err := htmlMarkdown.Convert([]byte(doc.Prompt), &body); err != nil {
+		return fmt.Errorf("failed to render markdown: %w", err)
+	}
+
+	title := oneLine(doc.Task)
+	if title == "" {
+		title = "PromptGo prompt"
+	}
+
+	return htmlPage.Execute(w, struct {
+		Title   string
+		Doc     Document
+		Created string
+		QA      []qaPair
+		Body    template.HTML
+	}{
+		Title:   title,
+		Doc:     doc,
+		Created: doc.CreatedAt.UTC().Format(time.RFC3339),
+		QA:      doc.sortedQA(),
+		Body:    template.HTML(body.String()),
+	})
+}
@@ -0,0 +1,132 @@
+package export
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var created = time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+
+func TestMarkdownFrontMatter(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  Document
+		want frontMatter
+	}{
+		{
+			name: "all fields",
+			doc: Document{
+				Prompt:     "# Task\n\nAdd a cache.",
+				Task:       "add a cache",
+				Details:    "keep it in memory",
+				TaskType:   "feature",
+				Model:      "claude-sonnet-4",
+				SecretWord: "banana",
+				CreatedAt:  created,
+				QA:         map[string]string{"Which store?": "Redis", "Eviction?": "LRU"},
+			},
+			want: frontMatter{
+				Task:       "add a cache",
+				Details:    "keep it in memory",
+				Type:       "feature",
+				Model:      "claude-sonnet-4",
+				SecretWord: "banana",
+				CreatedAt:  "2026-03-04T05:06:07Z",
+				QA:         []qaPair{{Question: "Eviction?", Answer: "LRU"}, {Question: "Which store?", Answer: "Redis"}},
+			},
+		},
+		{
+			name: "values that need quoting",
+			doc: Document{
+				Prompt:     "body",
+				Task:       "fix: the --- separator\nand a second line",
+				SecretWord: "#yes",
+				CreatedAt:  created.In(time.FixedZone("CET", 3600)),
+			},
+			want: frontMatter{
+				Task:       "fix: the --- separator\nand a second line",
+				SecretWord: "#yes",
+				CreatedAt:  "2026-03-04T05:06:07Z",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Bytes(tt.doc, Markdown{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rest, ok := strings.CutPrefix(string(out), "---\n")
+			if !ok {
+				t.Fatalf("export doesn't start with front matter:\n%s", out)
+			}
+			meta, body, ok := strings.Cut(rest, "\n---\n\n")
+			if !ok {
+				t.Fatalf("front matter isn't closed:\n%s", out)
+			}
+
+			var got frontMatter
+			if err := yaml.Unmarshal([]byte(meta), &got); err != nil {
+				t.Fatalf("front matter isn't valid YAML: %v\n%s", err, meta)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("front matter = %+v, want %+v", got, tt.want)
+			}
+			if want := strings.TrimSpace(tt.doc.Prompt) + "\n"; body != want {
+				t.Errorf("body = %q, want %q", body, want)
+			}
+		})
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	doc := Document{Prompt: "# Task", Task: "add a cache", SecretWord: "banana", CreatedAt: created, QA: map[string]string{"b": "2", "a": "1"}}
+	out, err := Bytes(doc, JSON{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got jsonDocument
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := jsonDocument{Task: "add a cache", SecretWord: "banana", CreatedAt: "2026-03-04T05:06:07Z", QA: []qaPair{{"a", "1"}, {"b", "2"}}, Prompt: "# Task"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("JSON = %+v, want %+v", got, want)
+	}
+}
+
+func TestHTMLEscapes(t *testing.T) {
+	doc := Document{Prompt: "# Title\n\n<script>alert(1)</script>", Task: "<b>task</b>", CreatedAt: created}
+	out, err := Bytes(doc, HTML{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(out)
+	for _, bad := range []string{"<script>", "<b>task</b>"} {
+		if strings.Contains(html, bad) {
+			t.Errorf("HTML export contains %q unescaped", bad)
+		}
+	}
+	if !strings.Contains(html, "<h1>Title</h1>") {
+		t.Errorf("HTML export doesn't render the markdown:\n%s", html)
+	}
+}
+
+func TestExporterByName(t *testing.T) {
+	for _, name := range []string{"text", "Markdown", "JSON", "html"} {
+		if _, ok := ExporterByName(name); !ok {
+			t.Errorf("ExporterByName(%q) not found", name)
+		}
+	}
+	if _, ok := ExporterByName("pdf"); ok {
+		t.Error("ExporterByName(\"pdf\") found an exporter")
+	}
+}
@@ -0,0 +1,208 @@
+package feedback
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Rating is a thumbs up or down
+type Rating string
+
+const (
+	RatingUp   Rating = "up"
+	RatingDown Rating = "down"
+)
+
+// Entry is one piece of feedback on a generated prompt
+type Entry struct {
+	Time          time.Time `json:"time"`
+	User          string    `json:"user"`
+	PromptID      string    `json:"prompt_id"`
+	PromptVersion string    `json:"prompt_version,omitempty"`
+	Model         string    `json:"model,omitempty"`
+	Rating        Rating    `json:"rating,omitempty"`
+	Comment       string    `json:"comment,omitempty"`
+
+	// Answer to "did the agent respect the secret-word gate?", nil when not asked
+	GateRespected *bool `json:"gate_respected,omitempty"`
+
+	// How each candidate fared when the user picked or merged candidates, empty otherwise
+	Candidates []CandidateChoice `json:"candidates,omitempty"`
+}
+
+// CandidateChoice is one candidate the user was offered alongside others
+type CandidateChoice struct {
+	Setting   string `json:"setting"`          // Model, temperature and template, as from enhancer.CandidateSpec.Key
+	Chosen    bool   `json:"chosen,omitempty"` // Picked, or contributed sections to a merge
+	Discarded bool   `json:"discarded,omitempty"`
+}
+
+// SettingStats counts how often candidates with one setting were offered and chosen
+type SettingStats struct {
+	Shown  int
+	Chosen int
+}
+
+// Rate estimates the chance the setting is chosen. It starts from even odds so settings with
+// little history still get offered.
+func (s SettingStats) Rate() float64 {
+	return float64(s.Chosen+1) / float64(s.Shown+2)
+}
+
+// PromptID identifies a prompt by its content
+func PromptID(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Summary aggregates feedback for one system prompt version
+type Summary struct {
+	PromptVersion string
+	Up            int
+	Down          int
+	Comments      int
+	GateAsked     int
+	GateRespected int
+}
+
+// Approval is the share of ratings that were thumbs up, or 0 without ratings
+func (s Summary) Approval() float64 {
+	if s.Up+s.Down == 0 {
+		return 0
+	}
+	return float64(s.Up) / float64(s.Up+s.Down)
+}
+
+// Store appends feedback to a JSONL file and keeps the entries in memory
+type Store struct {
+	mu      sync.Mutex
+	path    string // Empty keeps feedback in memory only
+	entries []Entry
+}
+
+// NewStore opens the feedback log at path, loading the entries already in it
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path}
+	if path == "" {
+		return s, nil
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open feedback log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20) // Comments can be long
+	for line := 1; scanner.Scan(); line++ {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		s.entries = append(s.entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read feedback log: %w", err)
+	}
+	return s, nil
+}
+
+// Add records an entry
+func (s *Store) Add(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = append(s.entries, e)
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// Summaries aggregates the feedback by prompt version, sorted by version
+func (s *Store) Summaries() []Summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byVersion := make(map[string]*Summary)
+	for _, e := range s.entries {
+		if len(e.Candidates) > 0 {
+			// Candidate choices aren't feedback on a prompt version
+			continue
+		}
+		sum := byVersion[e.PromptVersion]
+		if sum == nil {
+			sum = &Summary{PromptVersion: e.PromptVersion}
+			byVersion[e.PromptVersion] = sum
+		}
+		switch e.Rating {
+		case RatingUp:
+			sum.Up++
+		case RatingDown:
+			sum.Down++
+		}
+		if e.Comment != "" {
+			sum.Comments++
+		}
+		if e.GateRespected != nil {
+			sum.GateAsked++
+			if *e.GateRespected {
+				sum.GateRespected++
+			}
+		}
+	}
+
+	summaries := make([]Summary, 0, len(byVersion))
+	for _, sum := range byVersion {
+		summaries = append(summaries, *sum)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].PromptVersion < summaries[j].PromptVersion
+	})
+	return summaries
+}
+
+// CandidateStats aggregates the candidate choices by setting
+func (s *Store) CandidateStats() map[string]SettingStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make(map[string]SettingStats)
+	for _, e := range s.entries {
+		for _, c := range e.Candidates {
+			st := stats[c.Setting]
+			st.Shown++
+			if c.Chosen {
+				st.Chosen++
+			}
+			stats[c.Setting] = st
+		}
+	}
+	return stats
+}
@@ -0,0 +1,203 @@
+package history
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"promptgo/internal/ai"
+	"promptgo/internal/enhancer"
+	"promptgo/internal/feedback"
+)
+
+// maxEntries bounds each user's history; the least recently updated entries are dropped first
+const maxEntries = 100
+
+var ErrNotFound = errors.New("history entry not found")
+
+// Entry is a generated prompt kept in a user's history, with its revisions and what became of it
+type Entry struct {
+	ID      string    `json:"id"`
+	User    string    `json:"user"`
+	Title   string    `json:"title"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+
+	Input         enhancer.Input    `json:"input"` // Input the revisions were generated from, after redaction
+	TaskType      ai.TaskType       `json:"task_type,omitempty"`
+	QA            map[string]string `json:"qa,omitempty"`
+	Revisions     []ai.Revision     `json:"revisions"`
+	PromptVersion string            `json:"prompt_version,omitempty"` // Generator system prompt version of the first revision
+
+	Shares []string `json:"shares,omitempty"` // IDs of shares created from the entry
+
+	// Latest feedback given on the entry
+	Rating        feedback.Rating `json:"rating,omitempty"`
+	Comment       string          `json:"comment,omitempty"`
+	GateRespected *bool           `json:"gate_respected,omitempty"`
+}
+
+// Store keeps every user's history in memory and in a JSON file
+type Store struct {
+	mu      sync.RWMutex
+	path    string // Empty keeps history in memory only
+	entries map[string]Entry
+}
+
+// NewStore opens the history saved at path
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, entries: make(map[string]Entry)}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	var list []Entry
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for _, e := range list {
+		s.entries[e.ID] = e
+	}
+	return s, nil
+}
+
+// newID returns a random entry ID
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Save adds e to its user's history, or replaces the entry with its ID, and returns it as saved
+func (s *Store) Save(e Entry) (Entry, error) {
+	now := time.Now().UTC()
+	if e.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return Entry{}, fmt.Errorf("failed to generate history ID: %w", err)
+		}
+		e.ID = id
+	}
+	if e.Created.IsZero() {
+		e.Created = now
+	}
+	e.Updated = now
+	if e.PromptVersion == "" && len(e.Revisions) > 0 {
+		e.PromptVersion = e.Revisions[0].PromptVersion
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old, ok := s.entries[e.ID]; ok {
+		if old.User != e.User {
+			return Entry{}, ErrNotFound
+		}
+		// What became of the entry is kept when its revisions are saved again
+		e.Shares, e.Rating, e.Comment, e.GateRespected = old.Shares, old.Rating, old.Comment, old.GateRespected
+	}
+	s.entries[e.ID] = e
+	s.prune(e.User)
+	return e, s.save()
+}
+
+// Update changes user's entry id in place
+func (s *Store) Update(user, id string, fn func(*Entry)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[id]
+	if !ok || e.User != user {
+		return ErrNotFound
+	}
+	fn(&e)
+	s.entries[id] = e
+	return s.save()
+}
+
+// Get returns user's entry id
+func (s *Store) Get(user, id string) (Entry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.entries[id]
+	if !ok || e.User != user {
+		return Entry{}, false
+	}
+	return e, true
+}
+
+// List returns user's history, most recently updated first
+func (s *Store) List(user string) []Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.list(user)
+}
+
+// All returns every user's history, for aggregates across users
+func (s *Store) All() []Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		out = append(out, e)
+	}
+	return out
+}
+
+// list returns user's entries newest first; callers hold the lock
+func (s *Store) list(user string) []Entry {
+	var out []Entry
+	for _, e := range s.entries {
+		if e.User == user {
+			out = append(out, e)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Updated.After(out[j].Updated) })
+	return out
+}
+
+// prune drops user's oldest entries beyond maxEntries; callers hold the write lock
+func (s *Store) prune(user string) {
+	list := s.list(user)
+	for _, e := range list[min(maxEntries, len(list)):] {
+		delete(s.entries, e.ID)
+	}
+}
+
+// save writes every entry to the file; callers hold the write lock
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	list := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		list = append(list, e)
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	// Write then rename so a crash can't leave a truncated file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
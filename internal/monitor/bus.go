@@ -0,0 +1,48 @@
+package monitor
+
+import (
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Bus injects messages into the Bubble Tea program of every connected session
+type Bus struct {
+	sessions *Registry
+
+	mu     sync.Mutex
+	sticky tea.Msg // Last announcement, replayed to sessions that connect later
+}
+
+// NewBus creates a bus that delivers to the sessions in r
+func NewBus(r *Registry) *Bus {
+	return &Bus{sessions: r}
+}
+
+// Publish sends msg to every connected session and returns how many it was sent to.
+// It doesn't wait for the programs to take it, so a busy or stuck session can't hold up the caller.
+func (b *Bus) Publish(msg tea.Msg) int {
+	programs := b.sessions.programs()
+	for _, p := range programs {
+		go p.Send(msg)
+	}
+	return len(programs)
+}
+
+// Announce publishes msg and keeps it for sessions that connect later
+func (b *Bus) Announce(msg tea.Msg) int {
+	b.mu.Lock()
+	b.sticky = msg
+	b.mu.Unlock()
+	return b.Publish(msg)
+}
+
+// Replay sends the current announcement, if any, to a session that just connected
+func (b *Bus) Replay(p *tea.Program) {
+	b.mu.Lock()
+	msg := b.sticky
+	b.mu.Unlock()
+	if msg != nil {
+		go p.Send(msg)
+	}
+}
@@ -0,0 +1,103 @@
+package monitor
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"promptgo/internal/ai"
+)
+
+// maxErrors bounds the recent errors kept for the dashboard
+const maxErrors = 50
+
+// ModelHealth summarizes the calls made to one model
+type ModelHealth struct {
+	Model       string
+	Calls       int
+	Failures    int
+	LastLatency time.Duration
+	LastOK      time.Time
+	LastError   string
+	LastErrorAt time.Time
+}
+
+// Healthy reports whether the model's most recent call succeeded
+func (h ModelHealth) Healthy() bool {
+	return !h.LastOK.Before(h.LastErrorAt)
+}
+
+// ErrorEntry is one recent error
+type ErrorEntry struct {
+	Time    time.Time
+	Source  string // Model or session that failed
+	Message string
+}
+
+// Health records provider calls and recent errors
+type Health struct {
+	mu     sync.Mutex
+	models map[string]*ModelHealth
+	errors []ErrorEntry // Oldest first
+}
+
+// NewHealth creates an empty health record
+func NewHealth() *Health {
+	return &Health{models: make(map[string]*ModelHealth)}
+}
+
+// Observe records a provider call; pass it to Enhancer.SetObserver
+func (h *Health) Observe(e ai.CallEvent) {
+	h.mu.Lock()
+	m := h.models[e.Model]
+	if m == nil {
+		m = &ModelHealth{Model: e.Model}
+		h.models[e.Model] = m
+	}
+	m.Calls++
+	m.LastLatency = e.Latency
+	if e.Err == nil {
+		m.LastOK = time.Now()
+		h.mu.Unlock()
+		return
+	}
+	m.Failures++
+	m.LastError = e.Err.Error()
+	m.LastErrorAt = time.Now()
+	h.mu.Unlock()
+
+	h.Error(e.Model, e.Err.Error())
+}
+
+// Error records an error for the dashboard
+func (h *Health) Error(source, message string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.errors = append(h.errors, ErrorEntry{Time: time.Now(), Source: source, Message: message})
+	if len(h.errors) > maxErrors {
+		h.errors = h.errors[len(h.errors)-maxErrors:]
+	}
+}
+
+// Models returns the health of every model called, sorted by name
+func (h *Health) Models() []ModelHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	list := make([]ModelHealth, 0, len(h.models))
+	for _, m := range h.models {
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Model < list[j].Model })
+	return list
+}
+
+// Errors returns the recent errors, newest first
+func (h *Health) Errors() []ErrorEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	list := make([]ErrorEntry, len(h.errors))
+	for i, e := range h.errors {
+		list[len(h.errors)-1-i] = e
+	}
+	return list
+}
@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ErrNoSession is returned for session IDs that aren't connected
+var ErrNoSession = errors.New("no such session")
+
+// SessionInfo is a snapshot of a connected session
+type SessionInfo struct {
+	ID      int
+	User    string // Key fingerprint
+	Name    string // SSH username
+	Remote  string
+	State   string // The screen the session is on
+	Started time.Time
+}
+
+// session is a registered session and the handles used to reach it
+type session struct {
+	info    SessionInfo
+	program *tea.Program
+	close   func() error
+}
+
+// Registry tracks the interactive sessions connected to the server
+type Registry struct {
+	mu       sync.RWMutex
+	next     int
+	sessions map[int]*session
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{sessions: make(map[int]*session)}
+}
+
+// Add registers a session and returns its ID; close disconnects it
+func (r *Registry) Add(info SessionInfo, close func() error) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.next++
+	info.ID = r.next
+	r.sessions[info.ID] = &session{info: info, close: close}
+	return info.ID
+}
+
+// SetProgram attaches the session's Bubble Tea program so messages can be sent to it
+func (r *Registry) SetProgram(id int, p *tea.Program) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.sessions[id]; ok {
+		s.program = p
+	}
+}
+
+// SetState records the screen a session is on
+func (r *Registry) SetState(id int, state string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.sessions[id]; ok {
+		s.info.State = state
+	}
+}
+
+// Remove forgets a session once it disconnects
+func (r *Registry) Remove(id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, id)
+}
+
+// List returns every connected session, oldest first
+func (r *Registry) List() []SessionInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]SessionInfo, 0, len(r.sessions))
+	for _, s := range r.sessions {
+		list = append(list, s.info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// Kick disconnects a session
+func (r *Registry) Kick(id int) error {
+	r.mu.RLock()
+	s, ok := r.sessions[id]
+	r.mu.RUnlock()
+	if !ok {
+		return ErrNoSession
+	}
+	return s.close()
+}
+
+// programs returns the programs of every session that has one
+func (r *Registry) programs() []*tea.Program {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var list []*tea.Program
+	for _, s := range r.sessions {
+		if s.program != nil {
+			list = append(list, s.program)
+		}
+	}
+	return list
+}
@@ -0,0 +1,97 @@
+package monitor
+
+import (
+	"sort"
+	"sync"
+
+	"promptgo/internal/ai"
+)
+
+// Spend is the tokens a user has used since their quota was last reset
+type Spend struct {
+	User         string
+	Calls        int
+	InputTokens  int64
+	OutputTokens int64
+	Cost         float64 // Estimated, models without pricing count as zero
+}
+
+// Tokens is the total of input and output tokens
+func (s Spend) Tokens() int64 {
+	return s.InputTokens + s.OutputTokens
+}
+
+// Usage tracks token spend per user against an optional quota. Spend is kept in memory,
+// so a restart resets every quota.
+type Usage struct {
+	mu    sync.Mutex
+	quota int64 // Tokens per user, 0 for no limit
+	spend map[string]*Spend
+}
+
+// NewUsage creates a tracker that allows each user quota tokens; 0 means no limit
+func NewUsage(quota int64) *Usage {
+	return &Usage{quota: quota, spend: make(map[string]*Spend)}
+}
+
+// SetQuota changes the per-user limit, e.g. after a config reload
+func (u *Usage) SetQuota(quota int64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.quota = quota
+}
+
+// Add records the tokens of one model response
+func (u *Usage) Add(user, model string, inputTokens, outputTokens int64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	s := u.spend[user]
+	if s == nil {
+		s = &Spend{User: user}
+		u.spend[user] = s
+	}
+	s.Calls++
+	s.InputTokens += inputTokens
+	s.OutputTokens += outputTokens
+	cost, _ := ai.EstimateCost(model, inputTokens, outputTokens)
+	s.Cost += cost
+}
+
+// Exceeded reports whether user has spent their quota
+func (u *Usage) Exceeded(user string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	s := u.spend[user]
+	return u.quota > 0 && s != nil && s.Tokens() >= u.quota
+}
+
+// Reset clears a user's spend, restoring their full quota
+func (u *Usage) Reset(user string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	delete(u.spend, user)
+}
+
+// Quota returns the per-user limit, 0 for no limit
+func (u *Usage) Quota() int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.quota
+}
+
+// List returns every user's spend, biggest first
+func (u *Usage) List() []Spend {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	list := make([]Spend, 0, len(u.spend))
+	for _, s := range u.spend {
+		list = append(list, *s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Tokens() != list[j].Tokens() {
+			return list[i].Tokens() > list[j].Tokens()
+		}
+		return list[i].User < list[j].User
+	})
+	return list
+}
@@ -0,0 +1,300 @@
+package project
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	maxStackFrames = 10
+	maxCommits     = 10
+	gitTimeout     = 10 * time.Second
+)
+
+var (
+	commitLineRe = regexp.MustCompile(`^commit ([0-9a-f]{7,40})`)
+	onelineRe    = regexp.MustCompile(`^([0-9a-f]{7,40}) (.+)$`)
+	failRe       = regexp.MustCompile(`^\s*--- FAIL: (\S+)`)
+	testResultRe = regexp.MustCompile(`^\s*--- (FAIL|PASS|SKIP): `)
+	recoveredRe  = regexp.MustCompile(` \[recovered(, repanicked)?\]$`)
+	failPkgRe    = regexp.MustCompile(`^FAIL\s+(\S+)\s+[\d.]+s$`)
+	buildErrRe   = regexp.MustCompile(`^(\S+\.go:\d+(?::\d+)?): (.+)$`)
+	frameFileRe  = regexp.MustCompile(`^\s+(\S+\.go:\d+)`)
+)
+
+// Changes is structured evidence about recent work: a diff, commits and test failures
+type Changes struct {
+	Files          []FileChange
+	Commits        []Commit
+	FailingTests   []string
+	FailingPkgs    []string
+	Panics         []Panic
+	BuildErrors    []string
+	HasTestResults bool
+}
+
+// FileChange is a file touched by a diff and its line counts
+type FileChange struct {
+	Path    string
+	Added   int
+	Deleted int
+}
+
+// Commit is one entry from a git log
+type Commit struct {
+	Hash    string
+	Subject string
+}
+
+// Panic is a panic message and the top of its stack
+type Panic struct {
+	Message string
+	Frames  []string // "function file.go:line"
+}
+
+// ParseChanges extracts changed files, commits, failing tests, panics and build errors
+// from pasted text that may mix a unified diff, a git log and go test output
+func ParseChanges(text string) *Changes {
+	c := &Changes{}
+
+	var file *FileChange
+	var current *Panic
+	var frameFunc string
+	pendingCommit := "" // Hash from a full git log waiting for its subject line
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Panic stack frames come in pairs: the function, then an indented file:line
+		if current != nil {
+			switch {
+			case strings.HasPrefix(line, "goroutine "), strings.HasPrefix(line, "[signal "),
+				strings.TrimSpace(line) == "" && len(current.Frames) == 0:
+				continue
+			case strings.HasPrefix(strings.TrimSpace(line), "panic: ") && len(current.Frames) == 0:
+				// The original panic under a recovered one, indented below it before Go 1.23
+				continue
+			case frameFileRe.MatchString(line) && frameFunc != "":
+				if len(current.Frames) < maxStackFrames && !harnessFrame(frameFunc) {
+					current.Frames = append(current.Frames, frameFunc+" "+frameFileRe.FindStringSubmatch(line)[1])
+				}
+				frameFunc = ""
+				continue
+			case strings.HasPrefix(line, "created by "):
+				frameFunc = line
+				continue
+			case strings.HasSuffix(line, ")") && strings.Contains(line, "(") && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t"):
+				frameFunc = line[:strings.LastIndex(line, "(")]
+				continue
+			default:
+				c.Panics = append(c.Panics, *current)
+				current = nil
+			}
+		}
+
+		// A line that can't belong to a diff ends the current file
+		if file != nil && !isDiffLine(line) {
+			file = nil
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			c.Files = append(c.Files, FileChange{})
+			file = &c.Files[len(c.Files)-1]
+		case strings.HasPrefix(line, "+++ "):
+			// Plain unified diffs have no diff --git header, so +++ can start a file too
+			if file == nil {
+				c.Files = append(c.Files, FileChange{})
+				file = &c.Files[len(c.Files)-1]
+			}
+			path, _, _ := strings.Cut(strings.TrimPrefix(line, "+++ "), "\t") // diff -u follows the path with a timestamp
+			file.Path = strings.TrimPrefix(path, "b/")
+		case file != nil && (strings.HasPrefix(line, "--- a/") || strings.HasPrefix(line, "--- /dev/null")):
+			// Old file header; the new path on the +++ line is the one we keep
+		case file != nil && strings.HasPrefix(line, "+"):
+			file.Added++
+		case file != nil && strings.HasPrefix(line, "-"):
+			file.Deleted++
+		case file != nil:
+			// Hunk headers, context and metadata lines
+
+		case strings.HasPrefix(line, "panic: "):
+			current = &Panic{Message: recoveredRe.ReplaceAllString(strings.TrimPrefix(line, "panic: "), "")}
+			frameFunc = ""
+		case failRe.MatchString(line):
+			c.HasTestResults = true
+			c.FailingTests = appendUnique(c.FailingTests, failRe.FindStringSubmatch(line)[1])
+		case failPkgRe.MatchString(line):
+			c.HasTestResults = true
+			c.FailingPkgs = appendUnique(c.FailingPkgs, failPkgRe.FindStringSubmatch(line)[1])
+		case strings.HasPrefix(line, "ok  \t") || strings.HasPrefix(line, "--- PASS"):
+			c.HasTestResults = true
+		case buildErrRe.MatchString(line):
+			c.BuildErrors = appendUnique(c.BuildErrors, line)
+
+		case commitLineRe.MatchString(line):
+			pendingCommit = commitLineRe.FindStringSubmatch(line)[1]
+		case pendingCommit != "" && strings.HasPrefix(line, "    ") && strings.TrimSpace(line) != "":
+			c.addCommit(pendingCommit, strings.TrimSpace(line))
+			pendingCommit = ""
+		case onelineRe.MatchString(line):
+			m := onelineRe.FindStringSubmatch(line)
+			c.addCommit(m[1], m[2])
+		}
+	}
+	if current != nil {
+		c.Panics = append(c.Panics, *current)
+	}
+
+	return c
+}
+
+// diffLinePrefixes start every line that can appear inside a file's diff
+var diffLinePrefixes = []string{
+	" ", "+", "-", "@@", "\\", "diff --git", "index ", "new file", "deleted file",
+	"similarity", "rename ", "old mode", "new mode", "Binary files",
+}
+
+// isDiffLine reports whether line can appear inside a file's diff
+func isDiffLine(line string) bool {
+	if line == "" {
+		return true
+	}
+	if testResultRe.MatchString(line) {
+		// Test output pasted right after a diff, not a deleted line
+		return false
+	}
+	for _, prefix := range diffLinePrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// harnessFrame reports whether a stack frame belongs to the runtime or the test harness rather
+// than the code under test
+func harnessFrame(function string) bool {
+	return function == "panic" || strings.HasPrefix(function, "runtime.") ||
+		strings.HasPrefix(function, "testing.") || strings.HasPrefix(function, "created by ")
+}
+
+// ChangesFromRepo reads the working tree diff against HEAD and recent commits from a git repository
+func ChangesFromRepo(dir string) (*Changes, error) {
+	diff, err := git(dir, "diff", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	log, err := git(dir, "log", "--oneline", fmt.Sprintf("-n%d", maxCommits))
+	if err != nil {
+		return nil, err
+	}
+	return ParseChanges(diff + "\n" + log), nil
+}
+
+// git runs a git command in dir and returns its output
+func git(dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return string(out), nil
+}
+
+func (c *Changes) addCommit(hash, subject string) {
+	if len(c.Commits) < maxCommits {
+		c.Commits = append(c.Commits, Commit{Hash: hash, Subject: subject})
+	}
+}
+
+// Empty reports whether nothing useful was found
+func (c *Changes) Empty() bool {
+	return len(c.Files) == 0 && len(c.Commits) == 0 && len(c.FailingTests) == 0 &&
+		len(c.FailingPkgs) == 0 && len(c.Panics) == 0 && len(c.BuildErrors) == 0
+}
+
+// Label describes the changes briefly for display
+func (c *Changes) Label() string {
+	var parts []string
+	if len(c.Files) > 0 {
+		parts = append(parts, fmt.Sprintf("%d changed files", len(c.Files)))
+	}
+	if len(c.Commits) > 0 {
+		parts = append(parts, fmt.Sprintf("%d commits", len(c.Commits)))
+	}
+	if len(c.FailingTests) > 0 {
+		parts = append(parts, fmt.Sprintf("%d failing tests", len(c.FailingTests)))
+	}
+	if len(c.Panics) > 0 {
+		parts = append(parts, fmt.Sprintf("%d panics", len(c.Panics)))
+	}
+	if len(c.BuildErrors) > 0 {
+		parts = append(parts, fmt.Sprintf("%d build errors", len(c.BuildErrors)))
+	}
+	if len(parts) == 0 {
+		return "nothing recognized"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Summary renders the changes for the model, keeping it under limit bytes.
+// Failures come first since they matter most for bug fixes.
+func (c *Changes) Summary(limit int) string {
+	var b strings.Builder
+
+	if len(c.FailingTests) > 0 {
+		fmt.Fprintf(&b, "Failing tests: %s\n", strings.Join(c.FailingTests, ", "))
+	}
+	if len(c.FailingPkgs) > 0 {
+		fmt.Fprintf(&b, "Failing packages: %s\n", strings.Join(c.FailingPkgs, ", "))
+	}
+	for _, p := range c.Panics {
+		fmt.Fprintf(&b, "Panic: %s\n", p.Message)
+		for _, f := range p.Frames {
+			fmt.Fprintf(&b, "    at %s\n", f)
+		}
+	}
+	if len(c.BuildErrors) > 0 {
+		b.WriteString("Build errors:\n")
+		for _, e := range c.BuildErrors {
+			fmt.Fprintf(&b, "- %s\n", e)
+		}
+	}
+	if len(c.Files) > 0 {
+		b.WriteString("Changed files:\n")
+		for _, f := range c.Files {
+			fmt.Fprintf(&b, "- %s (+%d -%d)\n", f.Path, f.Added, f.Deleted)
+		}
+	}
+	if len(c.Commits) > 0 {
+		b.WriteString("Recent commits:\n")
+		for _, cm := range c.Commits {
+			fmt.Fprintf(&b, "- %s %s\n", cm.Hash, cm.Subject)
+		}
+	}
+
+	out := b.String()
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return strings.ToValidUTF8(out, "")
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,199 @@
+package project
+
+import (
+	"reflect"
+	"testing"
+)
+
+// sigsegvOutput is go test output for a nil pointer dereference in a test
+const sigsegvOutput = `--- FAIL: TestNil (0.00s)
+panic: runtime error: invalid memory address or nil pointer dereference [recovered, repanicked]
+[signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x543363]
+
+goroutine 6 [running]:
+testing.tRunner.func1.2({0x6b6ab0, 0x6ee030})
+	/usr/local/go/src/testing/testing.go:2123 +0x232
+testing.tRunner.func1()
+	/usr/local/go/src/testing/testing.go:2126 +0x329
+panic({0x6b6ab0?, 0x6ee030?})
+	/usr/local/go/src/runtime/panic.go:859 +0x125
+example.com/pt.deref(...)
+	/tmp/pt/p_test.go:7
+example.com/pt.TestNil(0x1de5f27ca248?)
+	/tmp/pt/p_test.go:11 +0x3
+testing.tRunner(0x1de5f27ca248, 0x6d44f8)
+	/usr/local/go/src/testing/testing.go:2193 +0xea
+created by testing.(*T).Run in goroutine 1
+	/usr/local/go/src/testing/testing.go:2258 +0x4d4
+FAIL	example.com/pt	0.004s
+FAIL
+`
+
+// repanickedOutput is go test output for a panic recovered and raised again, as printed since Go 1.23
+const repanickedOutput = `=== RUN   TestRecovered
+--- FAIL: TestRecovered (0.00s)
+panic: boom [recovered, repanicked]
+
+goroutine 6 [running]:
+testing.tRunner.func1.2({0x6b3e48, 0x563570})
+	/usr/local/go/src/testing/testing.go:2123 +0x232
+testing.tRunner.func1()
+	/usr/local/go/src/testing/testing.go:2126 +0x329
+panic({0x6b3e48?, 0x563570?})
+	/usr/local/go/src/runtime/panic.go:859 +0x125
+example.com/pt.TestRecovered.func1()
+	/tmp/pt/p_test.go:10 +0x25
+panic({0x6b3e48?, 0x563570?})
+	/usr/local/go/src/runtime/panic.go:859 +0x125
+example.com/pt.boom(...)
+	/tmp/pt/p_test.go:5
+example.com/pt.TestRecovered(0x2e17c3230248?)
+	/tmp/pt/p_test.go:13 +0x3f
+testing.tRunner(0x2e17c3230248, 0x6d4650)
+	/usr/local/go/src/testing/testing.go:2193 +0xea
+created by testing.(*T).Run in goroutine 1
+	/usr/local/go/src/testing/testing.go:2258 +0x4d4
+FAIL	example.com/pt	0.004s
+FAIL
+`
+
+// recoveredOutput is the same panic as printed before Go 1.23, with the original panic nested below
+const recoveredOutput = `--- FAIL: TestRecovered (0.00s)
+panic: boom [recovered]
+	panic: boom
+
+goroutine 6 [running]:
+testing.tRunner.func1.2({0x5f3e48, 0x563570})
+	/usr/local/go/src/testing/testing.go:1545 +0x238
+testing.tRunner.func1()
+	/usr/local/go/src/testing/testing.go:1548 +0x397
+panic({0x5f3e48?, 0x563570?})
+	/usr/local/go/src/runtime/panic.go:914 +0x21f
+example.com/pt.boom(...)
+	/tmp/pt/p_test.go:5
+example.com/pt.TestRecovered(0xc000007d40?)
+	/tmp/pt/p_test.go:13 +0x3f
+testing.tRunner(0xc000007d40, 0x5d4650)
+	/usr/local/go/src/testing/testing.go:1595 +0xff
+created by testing.(*T).Run in goroutine 1
+	/usr/local/go/src/testing/testing.go:1648 +0x3ad
+exit status 2
+FAIL	example.com/pt	0.004s
+`
+
+// diffThenTests is a git diff pasted straight before the go test output, with no blank line between
+const diffThenTests = `diff --git a/store.go b/store.go
+index 3b18e51..a9c2f0d 100644
+--- a/store.go
++++ b/store.go
+@@ -10,7 +10,8 @@ func (s *Store) Get(id string) *Item {
+ 	s.mu.Lock()
+-	defer s.mu.Unlock()
+-	return s.items[id]
++	item := s.items[id]
++	s.mu.Unlock()
++	return item
+ }
+--- FAIL: TestStoreGet (0.01s)
+    store_test.go:21: got nil, want item
+--- PASS: TestStorePut (0.00s)
+FAIL
+FAIL	example.com/store	0.012s
+`
+
+func TestParseChanges(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want *Changes
+	}{
+		{
+			name: "nil pointer panic",
+			text: sigsegvOutput,
+			want: &Changes{
+				FailingTests: []string{"TestNil"},
+				FailingPkgs:  []string{"example.com/pt"},
+				Panics: []Panic{{
+					Message: "runtime error: invalid memory address or nil pointer dereference",
+					Frames:  []string{"example.com/pt.deref /tmp/pt/p_test.go:7", "example.com/pt.TestNil /tmp/pt/p_test.go:11"},
+				}},
+				HasTestResults: true,
+			},
+		},
+		{
+			name: "repanicked",
+			text: repanickedOutput,
+			want: &Changes{
+				FailingTests: []string{"TestRecovered"},
+				FailingPkgs:  []string{"example.com/pt"},
+				Panics: []Panic{{
+					Message: "boom",
+					Frames: []string{
+						"example.com/pt.TestRecovered.func1 /tmp/pt/p_test.go:10",
+						"example.com/pt.boom /tmp/pt/p_test.go:5",
+						"example.com/pt.TestRecovered /tmp/pt/p_test.go:13",
+					},
+				}},
+				HasTestResults: true,
+			},
+		},
+		{
+			name: "recovered with nested panic",
+			text: recoveredOutput,
+			want: &Changes{
+				FailingTests: []string{"TestRecovered"},
+				FailingPkgs:  []string{"example.com/pt"},
+				Panics: []Panic{{
+					Message: "boom",
+					Frames:  []string{"example.com/pt.boom /tmp/pt/p_test.go:5", "example.com/pt.TestRecovered /tmp/pt/p_test.go:13"},
+				}},
+				HasTestResults: true,
+			},
+		},
+		{
+			name: "diff followed by test output",
+			text: diffThenTests,
+			want: &Changes{
+				Files:          []FileChange{{Path: "store.go", Added: 3, Deleted: 2}},
+				FailingTests:   []string{"TestStoreGet"},
+				FailingPkgs:    []string{"example.com/store"},
+				HasTestResults: true,
+			},
+		},
+		{
+			name: "plain unified diff",
+			text: "--- old/config.yaml\t2024-05-01\n+++ new/config.yaml\t2024-05-02\n@@ -1,3 +1,3 @@\n ----\n-model: a\n+model: b\n",
+			want: &Changes{
+				Files: []FileChange{{Path: "new/config.yaml", Added: 1, Deleted: 1}},
+			},
+		},
+		{
+			name: "deleted line starting with dashes",
+			text: "diff --git a/notes.md b/notes.md\n--- a/notes.md\n+++ b/notes.md\n@@ -1,2 +1 @@\n title\n----\n",
+			want: &Changes{
+				Files: []FileChange{{Path: "notes.md", Deleted: 1}},
+			},
+		},
+		{
+			name: "git logs",
+			text: "commit 9748437c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a\nAuthor: Dev <dev@example.com>\nDate:   Mon May 6 10:00:00 2024 +0200\n\n    Fix the budget\n\n06765ef Let fallbacks use another provider\n",
+			want: &Changes{
+				Commits: []Commit{{Hash: "9748437c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a", Subject: "Fix the budget"}, {Hash: "06765ef", Subject: "Let fallbacks use another provider"}},
+			},
+		},
+		{
+			name: "build errors",
+			text: "# example.com/pt\n./p.go:12:3: undefined: foo\n./p.go:12:3: undefined: foo\n./q.go:4:2: \"os\" imported and not used\n",
+			want: &Changes{
+				BuildErrors: []string{"./p.go:12:3: undefined: foo", "./q.go:4:2: \"os\" imported and not used"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseChanges(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseChanges() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+package project
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Interface is an exported interface and the project types that appear to implement it
+type Interface struct {
+	Name         string
+	Methods      []string
+	Implementers []string // Matched by method names, since sources aren't type-checked
+}
+
+// TestPatterns describes how the project's existing tests are written
+type TestPatterns struct {
+	Files       int
+	TableDriven int // Files declaring a []struct{...} table of cases
+	Subtests    int // Files calling t.Run
+	Testify     bool
+	Gomock      bool
+}
+
+// pkgBuilder accumulates the API of one package across its files
+type pkgBuilder struct {
+	name       string
+	types      map[string]bool
+	interfaces map[string][]string        // Interface -> method names
+	methods    map[string]map[string]bool // Receiver type -> method names
+	funcs      []string
+	errors     map[string]bool
+}
+
+func newPkgBuilder() *pkgBuilder {
+	return &pkgBuilder{
+		types:      make(map[string]bool),
+		interfaces: make(map[string][]string),
+		methods:    make(map[string]map[string]bool),
+		errors:     make(map[string]bool),
+	}
+}
+
+// addGoFile parses a Go source file into its package's API, skipping files that don't parse
+func (c *collector) addGoFile(rel string, data []byte) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, rel, data, parser.SkipObjectResolution)
+	if err != nil {
+		return
+	}
+
+	if strings.HasSuffix(rel, "_test.go") {
+		c.addTestFile(file)
+		return
+	}
+
+	dir := pathDir(rel)
+	pkg, ok := c.packages[dir]
+	if !ok {
+		pkg = newPkgBuilder()
+		c.packages[dir] = pkg
+	}
+	pkg.name = file.Name.Name
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			pkg.addGenDecl(d)
+		case *ast.FuncDecl:
+			pkg.addFuncDecl(fset, d)
+		}
+	}
+}
+
+// addGenDecl records exported types, interfaces and sentinel errors
+func (p *pkgBuilder) addGenDecl(d *ast.GenDecl) {
+	for _, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			if !s.Name.IsExported() {
+				continue
+			}
+			p.types[s.Name.Name] = true
+			if iface, ok := s.Type.(*ast.InterfaceType); ok {
+				var methods []string
+				for _, m := range iface.Methods.List {
+					for _, name := range m.Names {
+						methods = append(methods, name.Name)
+					}
+				}
+				p.interfaces[s.Name.Name] = methods
+			}
+		case *ast.ValueSpec:
+			for _, name := range s.Names {
+				if name.IsExported() && strings.HasPrefix(name.Name, "Err") {
+					p.errors[name.Name] = true
+				}
+			}
+		}
+	}
+}
+
+// addFuncDecl records exported signatures and the method sets used to match implementers
+func (p *pkgBuilder) addFuncDecl(fset *token.FileSet, d *ast.FuncDecl) {
+	if d.Recv != nil && len(d.Recv.List) > 0 {
+		recv := receiverType(d.Recv.List[0].Type)
+		if p.methods[recv] == nil {
+			p.methods[recv] = make(map[string]bool)
+		}
+		p.methods[recv][d.Name.Name] = true
+
+		if d.Name.Name == "Error" && d.Type.Params.NumFields() == 0 && d.Type.Results.NumFields() == 1 {
+			p.errors[recv] = true
+		}
+		if !ast.IsExported(recv) {
+			return
+		}
+	}
+	if !d.Name.IsExported() {
+		return
+	}
+
+	// Print the declaration without its body to get the signature
+	var buf bytes.Buffer
+	sig := &ast.FuncDecl{Recv: d.Recv, Name: d.Name, Type: d.Type}
+	if err := printer.Fprint(&buf, fset, sig); err == nil {
+		p.funcs = append(p.funcs, buf.String())
+	}
+}
+
+// addTestFile records which testing conventions a test file follows
+func (c *collector) addTestFile(file *ast.File) {
+	t := &c.ctx.Tests
+	t.Files++
+
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		switch {
+		case strings.HasPrefix(path, "github.com/stretchr/testify"):
+			t.Testify = true
+		case path == "github.com/golang/mock/gomock" || path == "go.uber.org/mock/gomock":
+			t.Gomock = true
+		}
+	}
+
+	tableDriven, subtests := false, false
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CompositeLit:
+			if arr, ok := n.Type.(*ast.ArrayType); ok {
+				if _, ok := arr.Elt.(*ast.StructType); ok {
+					tableDriven = true
+				}
+			}
+		case *ast.CallExpr:
+			if sel, ok := n.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Run" {
+				if id, ok := sel.X.(*ast.Ident); ok && id.Name == "t" {
+					subtests = true
+				}
+			}
+		}
+		return true
+	})
+	if tableDriven {
+		t.TableDriven++
+	}
+	if subtests {
+		t.Subtests++
+	}
+}
+
+// build converts the accumulated package into its exported form, matching interface implementers across the project
+func (p *pkgBuilder) build(path string, all map[string]*pkgBuilder) Package {
+	pkg := Package{
+		Path:       path,
+		Name:       p.name,
+		Types:      sortedKeys(p.types),
+		Funcs:      p.funcs,
+		ErrorTypes: sortedKeys(p.errors),
+	}
+
+	names := make([]string, 0, len(p.interfaces))
+	for name := range p.interfaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		iface := Interface{Name: name, Methods: p.interfaces[name]}
+		if len(iface.Methods) > 0 {
+			iface.Implementers = findImplementers(iface.Methods, all)
+		}
+		pkg.Interfaces = append(pkg.Interfaces, iface)
+	}
+
+	return pkg
+}
+
+// findImplementers returns project types whose methods include every one of methods
+func findImplementers(methods []string, all map[string]*pkgBuilder) []string {
+	var impls []string
+	for dir, pkg := range all {
+		for typ, set := range pkg.methods {
+			ok := true
+			for _, m := range methods {
+				if !set[m] {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				impls = append(impls, qualifiedName(dir, pkg.name, typ))
+			}
+		}
+	}
+	sort.Strings(impls)
+	return impls
+}
+
+// APIMap renders the packages most relevant to query as a compact API reference under limit bytes
+func (c *Context) APIMap(query string, limit int) string {
+	var b strings.Builder
+
+	if t := c.Tests; t.Files > 0 {
+		fmt.Fprintf(&b, "Test conventions: %d test files, table-driven in %d, t.Run subtests in %d, testify: %s, gomock: %s\n",
+			t.Files, t.TableDriven, t.Subtests, yesNo(t.Testify), yesNo(t.Gomock))
+	}
+
+	for _, pkg := range c.rankPackages(query) {
+		block := pkg.apiBlock()
+		if block == "" {
+			continue
+		}
+		if b.Len()+len(block) > limit {
+			break
+		}
+		b.WriteString(block)
+	}
+
+	return b.String()
+}
+
+// apiBlock renders one package's API
+func (p Package) apiBlock() string {
+	if len(p.Interfaces) == 0 && len(p.Funcs) == 0 && len(p.Types) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Package %s (%s):\n", p.Path, p.Name)
+	if len(p.Types) > 0 {
+		fmt.Fprintf(&b, "  types: %s\n", strings.Join(p.Types, ", "))
+	}
+	for _, iface := range p.Interfaces {
+		fmt.Fprintf(&b, "  interface %s { %s }", iface.Name, strings.Join(iface.Methods, ", "))
+		if len(iface.Implementers) > 0 {
+			fmt.Fprintf(&b, " implemented by %s", strings.Join(iface.Implementers, ", "))
+		}
+		b.WriteString("\n")
+	}
+	for _, fn := range p.Funcs {
+		fmt.Fprintf(&b, "  %s\n", fn)
+	}
+	if len(p.ErrorTypes) > 0 {
+		fmt.Fprintf(&b, "  errors: %s\n", strings.Join(p.ErrorTypes, ", "))
+	}
+	return b.String()
+}
+
+// rankPackages orders packages by how many words from query appear in their names and API
+func (c *Context) rankPackages(query string) []Package {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	scores := make(map[string]int)
+	for _, pkg := range c.Packages {
+		haystack := strings.ToLower(pkg.Path + " " + pkg.Name + " " + strings.Join(pkg.Types, " ") + " " + strings.Join(pkg.Funcs, " "))
+		for _, w := range words {
+			if len(w) >= 3 && strings.Contains(haystack, w) {
+				scores[pkg.Path]++
+			}
+		}
+	}
+
+	ranked := append([]Package(nil), c.Packages...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i].Path] > scores[ranked[j].Path]
+	})
+	return ranked
+}
+
+// receiverType returns the base type name of a method receiver
+func receiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.IndexListExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// qualifiedName prefixes a type with its package name, or the directory for the root package
+func qualifiedName(dir, pkgName, typ string) string {
+	if pkgName == "" {
+		pkgName = dir
+	}
+	return pkgName + "." + typ
+}
+
+func pathDir(rel string) string {
+	if i := strings.LastIndex(rel, "/"); i >= 0 {
+		return rel[:i]
+	}
+	return "."
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
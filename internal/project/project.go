@@ -0,0 +1,303 @@
+package project
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"promptgo/internal/templates"
+)
+
+const (
+	SummaryLimit = 8000 // Bounds the project summary sent to the model
+	APIMapLimit  = 6000 // Bounds the Go API map sent with prompt generation
+	ChangesLimit = 6000 // Bounds the diff, commit and test failure summary
+)
+
+const (
+	maxFileSize    = 1 << 20  // Files larger than 1MB are skipped
+	maxArchiveSize = 50 << 20 // Uploads are cut off after 50MB
+	maxFiles       = 5000     // Files beyond this are ignored
+)
+
+// Context is what we know about a user's project
+type Context struct {
+	Name         string // Directory or archive the context came from
+	Module       string
+	GoVersion    string
+	Dependencies []string
+	Packages     []Package
+	Tests        TestPatterns
+	Readme       string
+	Manifests    []string // Top-level build files such as go.mod, package.json or Cargo.toml
+}
+
+// Package is a Go package directory and its exported API
+type Package struct {
+	Path       string
+	Name       string
+	Types      []string
+	Interfaces []Interface
+	Funcs      []string // Exported function and method signatures
+	ErrorTypes []string // Types implementing error and Err* sentinel variables
+}
+
+// collector accumulates project files as they're read from a directory or archive
+type collector struct {
+	ctx      *Context
+	packages map[string]*pkgBuilder // Package directory -> API so far
+	files    int
+}
+
+// FromDir reads project context from a local directory
+func FromDir(dir string) (*Context, error) {
+	c := newCollector(filepath.Base(dir))
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if rel != "." && skipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !c.wants(rel) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil || info.Size() > maxFileSize {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		c.add(rel, data)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project directory: %w", err)
+	}
+
+	return c.finish(), nil
+}
+
+// FromTarball reads project context from a tar archive, optionally gzip-compressed
+func FromTarball(name string, r io.Reader) (*Context, error) {
+	br := bufio.NewReader(io.LimitReader(r, maxArchiveSize))
+
+	// Detect gzip by its magic bytes so both .tar and .tar.gz uploads work
+	var tr *tar.Reader
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzip archive: %w", err)
+		}
+		defer gz.Close()
+		tr = tar.NewReader(gz)
+	} else {
+		tr = tar.NewReader(br)
+	}
+
+	c := newCollector(name)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar archive: %w", err)
+		}
+
+		rel := strings.TrimPrefix(path.Clean(hdr.Name), "./")
+		if hdr.Typeflag != tar.TypeReg || hdr.Size > maxFileSize || inSkippedDir(rel) || !c.wants(rel) {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		c.add(rel, data)
+	}
+
+	return c.finish(), nil
+}
+
+func newCollector(name string) *collector {
+	return &collector{
+		ctx:      &Context{Name: name},
+		packages: make(map[string]*pkgBuilder),
+	}
+}
+
+// wants reports whether a file is worth reading
+func (c *collector) wants(rel string) bool {
+	if c.files >= maxFiles {
+		return false
+	}
+	base := path.Base(rel)
+	return base == "go.mod" || isReadme(rel) || strings.HasSuffix(base, ".go") || templates.IsManifest(rel)
+}
+
+// add records a file's contribution to the project context
+func (c *collector) add(rel string, data []byte) {
+	c.files++
+
+	if templates.IsManifest(rel) {
+		c.ctx.Manifests = append(c.ctx.Manifests, rel)
+	}
+
+	switch {
+	case rel == "go.mod":
+		c.ctx.Module, c.ctx.GoVersion, c.ctx.Dependencies = parseGoMod(data)
+	case isReadme(rel) && c.ctx.Readme == "":
+		c.ctx.Readme = string(data)
+	case strings.HasSuffix(rel, ".go"):
+		c.addGoFile(rel, data)
+	}
+}
+
+// finish sorts the collected packages into the context
+func (c *collector) finish() *Context {
+	for dir, pkg := range c.packages {
+		c.ctx.Packages = append(c.ctx.Packages, pkg.build(dir, c.packages))
+	}
+	sort.Slice(c.ctx.Packages, func(i, j int) bool {
+		return c.ctx.Packages[i].Path < c.ctx.Packages[j].Path
+	})
+
+	return c.ctx
+}
+
+// parseGoMod extracts the module path, Go version and direct dependencies from a go.mod file
+func parseGoMod(data []byte) (module, goVersion string, deps []string) {
+	inRequire := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if i := strings.Index(line, "//"); i >= 0 {
+			// Indirect dependencies add noise without telling us what the project uses
+			if strings.Contains(line[i:], "indirect") {
+				continue
+			}
+			line = strings.TrimSpace(line[:i])
+		}
+
+		switch {
+		case strings.HasPrefix(line, "module "):
+			module = strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`)
+		case strings.HasPrefix(line, "go "):
+			goVersion = strings.TrimSpace(strings.TrimPrefix(line, "go "))
+		case line == "require (":
+			inRequire = true
+		case inRequire && line == ")":
+			inRequire = false
+		case inRequire && line != "":
+			deps = append(deps, line)
+		case strings.HasPrefix(line, "require "):
+			deps = append(deps, strings.TrimSpace(strings.TrimPrefix(line, "require ")))
+		}
+	}
+	return module, goVersion, deps
+}
+
+// isReadme reports whether rel is the top-level README
+func isReadme(rel string) bool {
+	return !strings.Contains(rel, "/") && strings.HasPrefix(strings.ToUpper(rel), "README")
+}
+
+// skipDir reports whether a directory holds nothing worth summarizing
+func skipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata" || name == "node_modules"
+}
+
+// inSkippedDir reports whether any directory along rel should be skipped
+func inSkippedDir(rel string) bool {
+	parts := strings.Split(rel, "/")
+	for _, part := range parts[:len(parts)-1] {
+		if skipDir(part) {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary renders the context as text for the model, keeping it under limit bytes.
+// Sections are added in priority order and the README is truncated to fit what's left.
+func (c *Context) Summary(limit int) string {
+	var b bytes.Buffer
+
+	if c.Module != "" {
+		fmt.Fprintf(&b, "Module: %s", c.Module)
+		if c.GoVersion != "" {
+			fmt.Fprintf(&b, " (go %s)", c.GoVersion)
+		}
+		b.WriteString("\n")
+	}
+	if len(c.Dependencies) > 0 {
+		fmt.Fprintf(&b, "Dependencies: %s\n", strings.Join(c.Dependencies, ", "))
+	}
+	if len(c.Manifests) > 0 {
+		fmt.Fprintf(&b, "Build files: %s\n", strings.Join(c.Manifests, ", "))
+	}
+
+	if len(c.Packages) > 0 {
+		b.WriteString("Packages:\n")
+		for _, pkg := range c.Packages {
+			line := "- " + pkg.Path
+			if len(pkg.Types) > 0 {
+				line += ": " + strings.Join(pkg.Types, ", ")
+			}
+			if b.Len()+len(line)+1 > limit {
+				b.WriteString("- ...\n")
+				break
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+
+	if c.Readme != "" {
+		const header = "README:\n"
+		if remaining := limit - b.Len() - len(header); remaining > 0 {
+			readme := c.Readme
+			if len(readme) > remaining {
+				readme = readme[:remaining]
+			}
+			b.WriteString(header + readme)
+		}
+	}
+
+	out := b.String()
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return strings.ToValidUTF8(out, "")
+}
+
+// Label describes the context briefly for display
+func (c *Context) Label() string {
+	name := c.Module
+	if name == "" {
+		name = c.Name
+	}
+	return fmt.Sprintf("%s (%d packages)", name, len(c.Packages))
+}
@@ -0,0 +1,35 @@
+package project
+
+import "sync"
+
+// Store keeps the most recent value of one kind for each user
+type Store[T any] struct {
+	mu    sync.RWMutex
+	items map[string]T
+}
+
+// NewStore creates an empty store
+func NewStore[T any]() *Store[T] {
+	return &Store[T]{items: make(map[string]T)}
+}
+
+// Set replaces the stored value for a user
+func (s *Store[T]) Set(user string, v T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[user] = v
+}
+
+// Get returns the stored value for a user, or the zero value if none was set
+func (s *Store[T]) Get(user string) T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.items[user]
+}
+
+// Clear removes the stored value for a user
+func (s *Store[T]) Clear(user string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, user)
+}
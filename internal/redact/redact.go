@@ -0,0 +1,226 @@
+package redact
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Kind is a category of sensitive data
+type Kind string
+
+const (
+	KindPrivateKey Kind = "private key"
+	KindAPIKey     Kind = "API key"
+	KindToken      Kind = "token"
+	KindSecret     Kind = "high-entropy string"
+	KindEmail      Kind = "email"
+	KindIP         Kind = "IP address"
+)
+
+// Secret reports whether the kind is a credential, as opposed to personal or network data
+func (k Kind) Secret() bool {
+	return k != KindEmail && k != KindIP
+}
+
+// placeholderName is the label used inside redaction placeholders
+func (k Kind) placeholderName() string {
+	return strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_").Replace(string(k)))
+}
+
+// Finding is one piece of sensitive data found in text
+type Finding struct {
+	Kind  Kind
+	Value string
+}
+
+// Masked shows enough of the value to recognize it without repeating it
+func (f Finding) Masked() string {
+	v := f.Value
+	if i := strings.IndexByte(v, '\n'); i >= 0 {
+		v = v[:i]
+	}
+	if len(v) <= 8 {
+		return strings.Repeat("*", len(v))
+	}
+	return v[:4] + strings.Repeat("*", 6) + v[len(v)-2:]
+}
+
+// patterns are checked in order, so specific credential formats claim text before generic ones.
+// When a pattern has a capture group, only the group is sensitive.
+var patterns = []struct {
+	kind Kind
+	re   *regexp.Regexp
+}{
+	{KindPrivateKey, regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{KindAPIKey, regexp.MustCompile(`\b(?:sk-ant-[A-Za-z0-9_-]{20,}|sk-(?:proj-)?[A-Za-z0-9_-]{20,}|AKIA[0-9A-Z]{16}|AIza[0-9A-Za-z_-]{35}|gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,}|glpat-[A-Za-z0-9_-]{20,}|xox[abposr]-[A-Za-z0-9-]{10,}|sk_live_[A-Za-z0-9]{24,}|rk_live_[A-Za-z0-9]{24,})`)},
+	{KindToken, regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{KindToken, regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/-]{20,}=*`)},
+	{KindToken, regexp.MustCompile(`(?i)\b(?:password|passwd|secret|token|api_?key)\s*[:=]\s*["']?([^\s"']{8,})`)},
+	{KindEmail, regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)},
+	{KindIP, regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)},
+}
+
+// candidateRe matches runs of characters that secrets are usually encoded with
+var candidateRe = regexp.MustCompile(`[A-Za-z0-9+/_=-]{24,}`)
+
+const minEntropy = 4.0 // Bits per character; English words and identifiers stay well below
+
+// Scan finds API keys, tokens, private keys, emails and IP addresses in text, plus
+// long random-looking strings that are likely credentials in an unknown format
+func Scan(text string) []Finding {
+	var findings []Finding
+	seen := make(map[string]bool)
+	claimed := text // Matched values are blanked out so later patterns don't match inside them
+
+	add := func(kind Kind, value string) {
+		claimed = strings.ReplaceAll(claimed, value, strings.Repeat(" ", len(value)))
+		if !seen[value] {
+			seen[value] = true
+			findings = append(findings, Finding{Kind: kind, Value: value})
+		}
+	}
+
+	for _, p := range patterns {
+		// Matches index into the text as it was before this pattern, since add blanks out claimed
+		matched := claimed
+		for _, loc := range p.re.FindAllStringSubmatchIndex(matched, -1) {
+			start, end := loc[0], loc[1]
+			if len(loc) > 2 && loc[2] >= 0 {
+				start, end = loc[2], loc[3]
+			}
+			if p.kind == KindIP && !validIP(matched, start, end) {
+				continue
+			}
+			add(p.kind, matched[start:end])
+		}
+	}
+	for _, v := range candidateRe.FindAllString(claimed, -1) {
+		if looksRandom(v) {
+			add(KindSecret, v)
+		}
+	}
+
+	return findings
+}
+
+// HasSecrets reports whether any finding is a credential
+func HasSecrets(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Kind.Secret() {
+			return true
+		}
+	}
+	return false
+}
+
+// validIP filters out longer version numbers and loopback or unspecified addresses that reveal nothing
+func validIP(text string, start, end int) bool {
+	if (start > 0 && text[start-1] == '.') || (end < len(text) && text[end] == '.' && end+1 < len(text) && isDigit(text[end+1])) {
+		return false
+	}
+	ip := net.ParseIP(text[start:end])
+	return ip != nil && !ip.IsLoopback() && !ip.IsUnspecified()
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// looksRandom reports whether s mixes character classes with high entropy, unlike paths and identifiers
+func looksRandom(s string) bool {
+	var lower, upper, digit bool
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+	}
+	if !digit || !(lower || upper) {
+		return false
+	}
+	return entropy(s) >= minEntropy
+}
+
+// entropy returns the Shannon entropy of s in bits per character
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	var h float64
+	n := float64(len(s))
+	for _, c := range counts {
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// Mapping pairs each redacted value with the placeholder that replaced it
+type Mapping struct {
+	findings     []Finding
+	placeholders []string
+}
+
+// NewMapping assigns a numbered placeholder such as [REDACTED_API_KEY_1] to each finding
+func NewMapping(findings []Finding) *Mapping {
+	m := &Mapping{findings: findings}
+	counts := make(map[Kind]int)
+	for _, f := range findings {
+		counts[f.Kind]++
+		m.placeholders = append(m.placeholders, fmt.Sprintf("[REDACTED_%s_%d]", f.Kind.placeholderName(), counts[f.Kind]))
+	}
+	return m
+}
+
+// Add returns a mapping that also redacts findings, numbering their placeholders after the
+// existing ones so text already redacted keeps its placeholders. m may be nil.
+func (m *Mapping) Add(findings []Finding) *Mapping {
+	if m == nil {
+		return NewMapping(findings)
+	}
+	// Placeholders are numbered in order, so the existing findings get the same ones again
+	return NewMapping(append(slices.Clone(m.findings), findings...))
+}
+
+// Apply replaces every redacted value in text with its placeholder
+func (m *Mapping) Apply(text string) string {
+	if m == nil {
+		return text
+	}
+	// Longest values first so a value containing another is replaced whole
+	order := make([]int, len(m.findings))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return len(m.findings[order[a]].Value) > len(m.findings[order[b]].Value)
+	})
+	for _, i := range order {
+		text = strings.ReplaceAll(text, m.findings[i].Value, m.placeholders[i])
+	}
+	return text
+}
+
+// Restore puts emails and IP addresses back in place of their placeholders.
+// Credentials stay redacted so they don't end up in saved or shared prompts.
+func (m *Mapping) Restore(text string) string {
+	if m == nil {
+		return text
+	}
+	for i, f := range m.findings {
+		if !f.Kind.Secret() {
+			text = strings.ReplaceAll(text, m.placeholders[i], f.Value)
+		}
+	}
+	return text
+}
@@ -0,0 +1,58 @@
+package secret
+
+import "strings"
+
+const placeholder = "{{SECRET_WORD}}"
+
+// gateVerbs appear in instructions that hold back code until the secret word is said
+var gateVerbs = []string{"secret word", "code", "implement", "build", "write", "until", "once", "after", "wait"}
+
+// GateCheck reports whether a prompt still gates implementation behind the secret word
+type GateCheck struct {
+	Mentions    int  // Exact occurrences of the secret word
+	Instruction bool // A line pairs the secret word with a gating instruction
+	Placeholder bool // A placeholder survived, usually because the model changed its spelling
+}
+
+// CheckGate verifies that prompt contains the gate instruction with the exact secret word
+func CheckGate(prompt, word string) GateCheck {
+	var g GateCheck
+	lower := strings.ToLower(prompt)
+	g.Placeholder = strings.Contains(lower, strings.ToLower(placeholder)) || strings.Contains(lower, "secret_word")
+	if word == "" {
+		return g
+	}
+
+	g.Mentions = strings.Count(prompt, word)
+	for _, line := range strings.Split(prompt, "\n") {
+		if !strings.Contains(line, word) {
+			continue
+		}
+		l := strings.ToLower(line)
+		for _, v := range gateVerbs {
+			if strings.Contains(l, v) {
+				g.Instruction = true
+				break
+			}
+		}
+	}
+	return g
+}
+
+// OK reports whether the gate is intact
+func (g GateCheck) OK() bool {
+	return g.Mentions > 0 && g.Instruction && !g.Placeholder
+}
+
+// Problem describes what's wrong with the gate, or "" when it's intact
+func (g GateCheck) Problem() string {
+	switch {
+	case g.Placeholder:
+		return "the secret word placeholder wasn't filled in"
+	case g.Mentions == 0:
+		return "the secret word is missing"
+	case !g.Instruction:
+		return "the secret word appears but no instruction gates code behind it"
+	}
+	return ""
+}
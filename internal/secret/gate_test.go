@@ -0,0 +1,92 @@
+package secret
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckGate(t *testing.T) {
+	tests := []struct {
+		name    string
+		prompt  string
+		word    string
+		ok      bool
+		problem string
+	}{
+		{
+			name:   "gate intact",
+			prompt: "# Task\nAdd a cache.\n\nDo not write any code until I say the secret word \"banana\".",
+			word:   "banana",
+			ok:     true,
+		},
+		{
+			name:    "instruction on another line",
+			prompt:  "Wait before implementing.\nThe word is banana.",
+			word:    "banana",
+			problem: "the secret word appears but no instruction gates code behind it",
+		},
+		{
+			name:    "word missing",
+			prompt:  "Do not write code until I say the secret word.",
+			word:    "banana",
+			problem: "the secret word is missing",
+		},
+		{
+			name:    "word without a gate",
+			prompt:  "Use banana as the cache key prefix.",
+			word:    "banana",
+			problem: "the secret word appears but no instruction gates code behind it",
+		},
+		{
+			name:    "placeholder left",
+			prompt:  "Do not write code until I say {{SECRET_WORD}}. Then say banana.",
+			word:    "banana",
+			problem: "the secret word placeholder wasn't filled in",
+		},
+		{
+			name:    "spelling changed by the model",
+			prompt:  "Do not write code until I say Banana.",
+			word:    "banana",
+			problem: "the secret word is missing",
+		},
+		{
+			name:    "no word chosen",
+			prompt:  "Add a cache.",
+			problem: "the secret word is missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := CheckGate(tt.prompt, tt.word)
+			if g.OK() != tt.ok {
+				t.Errorf("OK() = %v, want %v (%+v)", g.OK(), tt.ok, g)
+			}
+			if g.Problem() != tt.problem {
+				t.Errorf("Problem() = %q, want %q", g.Problem(), tt.problem)
+			}
+		})
+	}
+}
+
+func TestWordStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.json")
+	s, err := NewWordStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Get("alice"); got != "" {
+		t.Errorf("Get() before Set = %q, want empty", got)
+	}
+	if err := s.Set("alice", "banana"); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewWordStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.Get("alice"); got != "banana" {
+		t.Errorf("Get() after reopening = %q, want %q", got, "banana")
+	}
+}
@@ -0,0 +1,72 @@
+package secret
+
+import (
+	"math/rand/v2"
+	"strings"
+)
+
+// defaultAdjectives and defaultNouns make memorable pairs that are unlikely to be typed by accident
+var defaultAdjectives = []string{
+	"amber", "brisk", "cobalt", "dusty", "ember", "fuzzy", "gentle", "hollow", "indigo", "jolly",
+	"keen", "lunar", "mellow", "nimble", "olive", "plucky", "quiet", "rusty", "silver", "tawny",
+	"umber", "velvet", "wistful", "zesty", "crimson", "frosty", "golden", "misty", "rapid", "sunny",
+}
+
+var defaultNouns = []string{
+	"falcon", "otter", "maple", "canyon", "lantern", "pebble", "walrus", "comet", "thistle", "harbor",
+	"badger", "meadow", "quartz", "puffin", "saddle", "tundra", "willow", "zephyr", "marmot", "kettle",
+	"glacier", "orchid", "raven", "tulip", "bison", "cactus", "dune", "heron", "ivy", "lagoon",
+}
+
+// codeWords are common in source code and chat about it, so a secret word built from one
+// could be said by accident and open the gate early
+var codeWords = map[string]bool{
+	"go": true, "run": true, "test": true, "build": true, "make": true, "main": true, "func": true,
+	"error": true, "string": true, "map": true, "type": true, "struct": true, "return": true, "true": true,
+	"false": true, "nil": true, "null": true, "none": true, "ok": true, "yes": true, "done": true,
+	"start": true, "begin": true, "code": true, "write": true, "implement": true, "next": true, "continue": true,
+	"proceed": true, "now": true, "ready": true, "class": true, "import": true, "async": true, "await": true,
+	"const": true, "let": true, "var": true, "new": true, "default": true, "select": true, "case": true,
+}
+
+// Generator produces memorable adjective-noun secret words
+type Generator struct {
+	adjectives []string
+	nouns      []string
+}
+
+// NewGenerator creates a generator from word lists, using the built-in lists for any that are empty.
+// With avoidCodeWords set, words common in code are dropped so the gate can't be opened by accident.
+func NewGenerator(adjectives, nouns []string, avoidCodeWords bool) *Generator {
+	if len(adjectives) == 0 {
+		adjectives = defaultAdjectives
+	}
+	if len(nouns) == 0 {
+		nouns = defaultNouns
+	}
+	if avoidCodeWords {
+		adjectives = filterCodeWords(adjectives, defaultAdjectives)
+		nouns = filterCodeWords(nouns, defaultNouns)
+	}
+	return &Generator{adjectives: adjectives, nouns: nouns}
+}
+
+// Generate returns a new word pair such as "amber-falcon"
+func (g *Generator) Generate() string {
+	return g.adjectives[rand.IntN(len(g.adjectives))] + "-" + g.nouns[rand.IntN(len(g.nouns))]
+}
+
+// filterCodeWords removes code words from words, falling back when nothing is left
+func filterCodeWords(words, fallback []string) []string {
+	var kept []string
+	for _, w := range words {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if w != "" && !codeWords[w] {
+			kept = append(kept, w)
+		}
+	}
+	if len(kept) == 0 {
+		return fallback
+	}
+	return kept
+}
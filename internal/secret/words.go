@@ -0,0 +1,74 @@
+package secret
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// WordStore remembers the last secret word each user chose, in a JSON file
+type WordStore struct {
+	mu    sync.Mutex
+	path  string // Empty keeps words in memory only
+	words map[string]string
+}
+
+// NewWordStore opens the secret words saved at path
+func NewWordStore(path string) (*WordStore, error) {
+	s := &WordStore{path: path, words: make(map[string]string)}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret words: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.words); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Get returns user's last secret word, or "" if they haven't chosen one
+func (s *WordStore) Get(user string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.words[user]
+}
+
+// Set remembers word as user's secret word; an unchanged word doesn't touch the file
+func (s *WordStore) Set(user, word string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.words[user] == word {
+		return nil
+	}
+	s.words[user] = word
+	return s.save()
+}
+
+// save writes every word to the file; callers hold the lock
+func (s *WordStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.words, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	// Write then rename so a crash can't leave a truncated file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,54 @@
+package share
+
+import (
+	"log"
+	"net/http"
+
+	"promptgo/internal/export"
+)
+
+// Handler serves link-access shares as read-only pages at /p/{id}, and as plain text at /p/{id}/raw.
+// Owner and workspace shares need an SSH key to view, so they look the same as missing ones here.
+func Handler(store *Store) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /p/{id}", func(w http.ResponseWriter, r *http.Request) {
+		sh, ok := linkShare(store, w, r)
+		if !ok {
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err := export.HTML{}.Export(w, export.Document{
+			Prompt:     sh.Prompt,
+			Task:       sh.Title,
+			SecretWord: sh.Secret,
+			CreatedAt:  sh.Created,
+		})
+		if err != nil {
+			log.Printf("Failed to render share %s: %v", sh.ID, err)
+		}
+	})
+	mux.HandleFunc("GET /p/{id}/raw", func(w http.ResponseWriter, r *http.Request) {
+		sh, ok := linkShare(store, w, r)
+		if !ok {
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(sh.Prompt))
+	})
+	return mux
+}
+
+// linkShare looks up the requested share, writing a 404 unless anyone with the link may view it
+func linkShare(store *Store, w http.ResponseWriter, r *http.Request) (Share, bool) {
+	// Shared prompts stay out of search engines, caches and referrer headers
+	w.Header().Set("X-Robots-Tag", "noindex")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	sh, err := store.Get(r.PathValue("id"))
+	if err != nil || sh.Access != AccessLink {
+		http.NotFound(w, r)
+		return Share{}, false
+	}
+	return sh, true
+}
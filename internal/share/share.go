@@ -0,0 +1,194 @@
+package share
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Access controls who can view a shared prompt
+type Access string
+
+const (
+	AccessOwner     Access = "owner"     // Only the user who shared it
+	AccessWorkspace Access = "workspace" // Members of the workspace it was shared from
+	AccessLink      Access = "link"      // Anyone with the ID, including over HTTP
+)
+
+// Accesses lists the access levels in the order the share dialog cycles through them
+var Accesses = []Access{AccessLink, AccessWorkspace, AccessOwner}
+
+var (
+	ErrNotFound  = errors.New("share not found")
+	ErrForbidden = errors.New("share belongs to someone else")
+)
+
+// Share is a prompt shared under an unguessable ID
+type Share struct {
+	ID        string    `json:"id"`
+	Owner     string    `json:"owner"`
+	Workspace string    `json:"workspace,omitempty"`
+	Access    Access    `json:"access"`
+	Title     string    `json:"title"`
+	Prompt    string    `json:"prompt"`
+	Secret    string    `json:"secret_word"` // The gate word in Prompt
+	Created   time.Time `json:"created"`
+	Expires   time.Time `json:"expires,omitempty"` // Zero never expires
+	Revoked   bool      `json:"revoked,omitempty"`
+}
+
+// Live reports whether the share can still be viewed
+func (s Share) Live(now time.Time) bool {
+	return !s.Revoked && (s.Expires.IsZero() || now.Before(s.Expires))
+}
+
+// CanView reports whether user, a member of workspaces, may view the share
+func (s Share) CanView(user string, workspaces []string) bool {
+	switch s.Access {
+	case AccessLink:
+		return true
+	case AccessWorkspace:
+		if s.Owner == user {
+			return true
+		}
+		for _, w := range workspaces {
+			if w == s.Workspace {
+				return true
+			}
+		}
+	case AccessOwner:
+		return s.Owner == user
+	}
+	return false
+}
+
+// newID returns a random ID that is impractical to guess
+func newID() (string, error) {
+	b := make([]byte, 15)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strings.ToLower(base32.StdEncoding.EncodeToString(b)), nil
+}
+
+// Store keeps shares in memory and in a JSON file
+type Store struct {
+	mu     sync.RWMutex
+	path   string // Empty keeps shares in memory only
+	shares map[string]Share
+}
+
+// NewStore opens the shares saved at path
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, shares: make(map[string]Share)}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shares: %w", err)
+	}
+	var list []Share
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for _, sh := range list {
+		s.shares[sh.ID] = sh
+	}
+	return s, nil
+}
+
+// Create assigns sh a new ID and saves it
+func (s *Store) Create(sh Share) (Share, error) {
+	id, err := newID()
+	if err != nil {
+		return Share{}, fmt.Errorf("failed to generate share ID: %w", err)
+	}
+	sh.ID = id
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shares[id] = sh
+	if err := s.save(); err != nil {
+		delete(s.shares, id)
+		return Share{}, err
+	}
+	return sh, nil
+}
+
+// Get returns a share that is still live
+func (s *Store) Get(id string) (Share, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sh, ok := s.shares[id]
+	if !ok || !sh.Live(time.Now()) {
+		return Share{}, ErrNotFound
+	}
+	return sh, nil
+}
+
+// Revoke stops a share from being viewed; only its owner can revoke it
+func (s *Store) Revoke(id, user string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sh, ok := s.shares[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if sh.Owner != user {
+		return ErrForbidden
+	}
+	sh.Revoked = true
+	s.shares[id] = sh
+	return s.save()
+}
+
+// Owned returns user's shares, newest first, including expired and revoked ones
+func (s *Store) Owned(user string) []Share {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Share
+	for _, sh := range s.shares {
+		if sh.Owner == user {
+			out = append(out, sh)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Created.After(out[j].Created) })
+	return out
+}
+
+// save writes every share to the file; callers hold the write lock
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	list := make([]Share, 0, len(s.shares))
+	for _, sh := range s.shares {
+		list = append(list, sh)
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	// Write then rename so a crash can't leave a truncated file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,130 @@
+package share
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCanView(t *testing.T) {
+	tests := []struct {
+		name       string
+		share      Share
+		user       string
+		workspaces []string
+		want       bool
+	}{
+		{"link", Share{Owner: "alice", Access: AccessLink}, "bob", nil, true},
+		{"owner only, owner", Share{Owner: "alice", Access: AccessOwner}, "alice", nil, true},
+		{"owner only, someone else", Share{Owner: "alice", Access: AccessOwner}, "bob", []string{"team"}, false},
+		{"workspace member", Share{Owner: "alice", Access: AccessWorkspace, Workspace: "team"}, "bob", []string{"other", "team"}, true},
+		{"workspace outsider", Share{Owner: "alice", Access: AccessWorkspace, Workspace: "team"}, "bob", []string{"other"}, false},
+		{"workspace owner who left", Share{Owner: "alice", Access: AccessWorkspace, Workspace: "team"}, "alice", nil, true},
+		{"unknown access", Share{Owner: "alice", Access: "public"}, "alice", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.share.CanView(tt.user, tt.workspaces); got != tt.want {
+				t.Errorf("CanView() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLive(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name  string
+		share Share
+		want  bool
+	}{
+		{"never expires", Share{}, true},
+		{"expires later", Share{Expires: now.Add(time.Hour)}, true},
+		{"expired", Share{Expires: now.Add(-time.Second)}, false},
+		{"revoked", Share{Revoked: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.share.Live(now); got != tt.want {
+				t.Errorf("Live() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreRevoke(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shares.json")
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh, err := s.Create(Share{Owner: "alice", Access: AccessLink, Prompt: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sh.ID) != 24 {
+		t.Errorf("ID %q is %d characters, want 24", sh.ID, len(sh.ID))
+	}
+
+	if err := s.Revoke(sh.ID, "bob"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Revoke() by someone else = %v, want ErrForbidden", err)
+	}
+	if err := s.Revoke("missing", "alice"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Revoke() of a missing share = %v, want ErrNotFound", err)
+	}
+	if err := s.Revoke(sh.ID, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Revoked shares are gone for viewers but still listed for their owner, across restarts
+	reopened, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reopened.Get(sh.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() of a revoked share = %v, want ErrNotFound", err)
+	}
+	if owned := reopened.Owned("alice"); len(owned) != 1 || !owned[0].Revoked {
+		t.Errorf("Owned() = %+v, want the revoked share", owned)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	s, _ := NewStore("")
+	link, _ := s.Create(Share{Owner: "alice", Access: AccessLink, Title: "cache", Prompt: "# Add a cache"})
+	private, _ := s.Create(Share{Owner: "alice", Access: AccessOwner, Prompt: "private"})
+	h := Handler(s)
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		body   string
+	}{
+		{"page", "/p/" + link.ID, http.StatusOK, ""},
+		{"raw", "/p/" + link.ID + "/raw", http.StatusOK, "# Add a cache"},
+		{"owner only share", "/p/" + private.ID, http.StatusNotFound, ""},
+		{"missing", "/p/nope/raw", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.body != "" && rec.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.body)
+			}
+			if got := rec.Header().Get("X-Robots-Tag"); got != "noindex" {
+				t.Errorf("X-Robots-Tag = %q, want noindex", got)
+			}
+		})
+	}
+}
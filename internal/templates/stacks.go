@@ -0,0 +1,171 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Stack is a language and ecosystem pack: how to phrase the methodology, test, and what to ask about
+type Stack struct {
+	ID            string
+	Name          string
+	Developer     string   // Who the methodology addresses, e.g. "a Go developer"
+	PhaseGuidance []string // Design concerns to raise while understanding and aligning
+	TestingIdioms string   // Replaces the generic testing question
+	QuestionHints []string // Topics worth asking about for this stack
+	keywords      []string // Words in the task that suggest this stack
+	manifests     []string // Top-level project files that identify this stack
+}
+
+// Stacks lists the supported packs; Go comes first and is the fallback
+var Stacks = []Stack{
+	{
+		ID:        "go",
+		Name:      "Go",
+		Developer: "a Go developer",
+		PhaseGuidance: []string{
+			"Package layout and which package owns the new code",
+			"Interfaces defined where they're consumed, kept small",
+			"Context propagation and goroutine lifetimes",
+		},
+		TestingIdioms: "Table-driven tests with t.Run subtests? Interfaces and fakes, or gomock/testify? Integration tests behind a build tag?",
+		QuestionHints: []string{"error wrapping and sentinel errors", "concurrency and cancellation", "standard library vs dependencies"},
+		keywords:      []string{"golang", "goroutine", "goroutines", "channel", "gin", "echo", "chi", "cobra", "gorm", "sqlc", "bubbletea", "grpc"},
+		manifests:     []string{"go.mod"},
+	},
+	{
+		ID:        "typescript",
+		Name:      "TypeScript/Node",
+		Developer: "a TypeScript/Node.js developer",
+		PhaseGuidance: []string{
+			"Module boundaries and where types are declared",
+			"Strict null checks, narrowing and runtime validation at the edges (zod or similar)",
+			"async/await flow, error propagation and unhandled rejections",
+		},
+		TestingIdioms: "Vitest or Jest? Unit tests with mocked modules, or integration tests against a real server? Type-level tests?",
+		QuestionHints: []string{"framework (Express, Fastify, Next.js, NestJS)", "package manager and monorepo layout", "ESM vs CommonJS"},
+		keywords:      []string{"typescript", "javascript", "node", "nodejs", "npm", "pnpm", "yarn", "deno", "bun", "react", "next", "nextjs", "express", "fastify", "nestjs", "vue", "angular", "tsx"},
+		manifests:     []string{"package.json", "tsconfig.json"},
+	},
+	{
+		ID:        "python",
+		Name:      "Python",
+		Developer: "a Python developer",
+		PhaseGuidance: []string{
+			"Module and package structure, public vs private names",
+			"Type hints and how strictly mypy/pyright is enforced",
+			"Sync vs asyncio, and exception hierarchy",
+		},
+		TestingIdioms: "pytest with fixtures and parametrize? unittest.mock or fakes? Property-based tests with hypothesis?",
+		QuestionHints: []string{"framework (Django, FastAPI, Flask)", "dependency and environment tooling (uv, poetry, pip)", "supported Python versions"},
+		keywords:      []string{"python", "django", "flask", "fastapi", "pytest", "pandas", "numpy", "pydantic", "asyncio", "celery", "pip", "poetry"},
+		manifests:     []string{"pyproject.toml", "requirements.txt", "setup.py", "Pipfile"},
+	},
+	{
+		ID:        "rust",
+		Name:      "Rust",
+		Developer: "a Rust developer",
+		PhaseGuidance: []string{
+			"Ownership and lifetimes across the new API",
+			"Error types (thiserror/anyhow) and where errors are converted",
+			"Crate and module layout, feature flags",
+		},
+		TestingIdioms: "Unit tests in #[cfg(test)] modules or integration tests in tests/? Doc tests? proptest for invariants?",
+		QuestionHints: []string{"async runtime (tokio or none)", "unsafe and performance constraints", "MSRV and no_std needs"},
+		keywords:      []string{"rust", "cargo", "crate", "crates", "tokio", "axum", "actix", "serde", "async-std", "wasm"},
+		manifests:     []string{"Cargo.toml"},
+	},
+	{
+		ID:        "java",
+		Name:      "Java",
+		Developer: "a Java developer",
+		PhaseGuidance: []string{
+			"Package structure and layering (controller, service, repository)",
+			"Dependency injection and object lifecycles",
+			"Checked vs unchecked exceptions, and null handling",
+		},
+		TestingIdioms: "JUnit 5 with Mockito? Spring slice tests or Testcontainers for integration?",
+		QuestionHints: []string{"framework (Spring Boot, Quarkus, Micronaut)", "Java version and build tool (Maven, Gradle)", "persistence (JPA, jOOQ, JDBC)"},
+		keywords:      []string{"java", "spring", "springboot", "maven", "gradle", "junit", "mockito", "jpa", "hibernate", "quarkus", "jvm"},
+		manifests:     []string{"pom.xml", "build.gradle", "build.gradle.kts"},
+	},
+}
+
+// StackByID looks up a stack pack, falling back to Go for unknown IDs
+func StackByID(id string) Stack {
+	for _, s := range Stacks {
+		if s.ID == id {
+			return s
+		}
+	}
+	return Stacks[0]
+}
+
+// IsManifest reports whether a top-level file name identifies a stack
+func IsManifest(name string) bool {
+	for _, s := range Stacks {
+		for _, m := range s.manifests {
+			if m == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DetectStack guesses the stack from the task text and the project's manifest files.
+// Manifests outweigh keywords since they describe the actual codebase.
+func DetectStack(text string, manifests []string) Stack {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+
+	best, bestScore := Stacks[0], 0
+	for _, s := range Stacks {
+		score := 0
+		for _, m := range manifests {
+			for _, sm := range s.manifests {
+				if m == sm {
+					score += 3
+				}
+			}
+		}
+		for _, w := range words {
+			for _, k := range s.keywords {
+				if w == k {
+					score++
+				}
+			}
+		}
+		if score > bestScore {
+			best, bestScore = s, score
+		}
+	}
+	return best
+}
+
+// Guidance renders the pack as context for the model
+func (s Stack) Guidance() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Stack: %s\n", s.Name)
+	b.WriteString("Design concerns:\n")
+	for _, g := range s.PhaseGuidance {
+		fmt.Fprintf(&b, "- %s\n", g)
+	}
+	fmt.Fprintf(&b, "Testing: %s\n", s.TestingIdioms)
+	fmt.Fprintf(&b, "Worth asking about: %s\n", strings.Join(s.QuestionHints, "; "))
+	return b.String()
+}
+
+// SystemPromptFor adapts the methodology template to a stack
+func SystemPromptFor(s Stack) string {
+	prompt := strings.Replace(SystemPrompt, "a Go developer", s.Developer, 1)
+	prompt = strings.Replace(prompt, "Table-driven tests? Mocks? Integration tests?", s.TestingIdioms, 1)
+
+	var concerns strings.Builder
+	for _, g := range s.PhaseGuidance {
+		fmt.Fprintf(&concerns, "   - %s\n", g)
+	}
+	return strings.Replace(prompt, "   - Any constraints I should know about?\n", "   - Any constraints I should know about?\n"+concerns.String(), 1)
+}
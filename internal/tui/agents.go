@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"promptgo/internal/export"
+)
+
+// SaveAgentFile returns a tea.Cmd that writes the prompt as an agent rule file in the current directory
+func SaveAgentFile(f export.AgentFile, title, body string) tea.Cmd {
+	return func() tea.Msg {
+		path, err := f.Write(".", title, body)
+		if err != nil {
+			return saveErrorMsg{err: err}
+		}
+		return saveSuccessMsg{path: path}
+	}
+}
+
+// rulesCommand is the command that downloads the saved prompt as rule file f, creating its
+// directory and appending to files that hold other instructions
+func rulesCommand(f export.AgentFile, title string, full bool) string {
+	path := f.Path(title)
+	command := "ssh … rules -saved -format " + f.ID
+	if full {
+		command += " -full"
+	}
+	if f.Shared() {
+		command += " >> " + path
+	} else {
+		command += " > " + path
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		command = "mkdir -p " + dir + " && " + command
+	}
+	return command
+}
+
+// updateAgentMenu handles the agent rule file menu
+func (m Model) updateAgentMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key := msg.String(); key {
+	case "esc", "a":
+		m.agentMenu = false
+		return m, nil
+
+	case "tab":
+		// Toggle between the conventions sections and the whole prompt
+		m.agentFullPrompt = !m.agentFullPrompt
+		return m, nil
+
+	default:
+		var i int
+		if _, err := fmt.Sscanf(key, "%d", &i); err != nil || i < 1 || i > len(export.AgentFiles) {
+			return m, nil
+		}
+		f, title := export.AgentFiles[i-1], export.DefaultTitle(m.input.Task)
+		m.agentMenu = false
+
+		// Over SSH the rules command converts the kept prompt on the client's side
+		if m.keepDocument != nil {
+			return m, KeepDocument(m.keepDocument, m.currentDocument(), rulesCommand(f, title, m.agentFullPrompt))
+		}
+
+		body := m.enhancedPrompt
+		if !m.agentFullPrompt {
+			body = export.Conventions(body)
+		}
+		return m, SaveAgentFile(f, title, body)
+	}
+}
+
+// viewAgentMenu renders the agent rule file choices
+func (m Model) viewAgentMenu() string {
+	var b strings.Builder
+
+	scope := "conventions sections"
+	if m.agentFullPrompt {
+		scope = "full prompt"
+	}
+	b.WriteString(FieldLabelStyle(true).Render("Save as agent rules (" + scope + "):"))
+	b.WriteString("\n")
+	for i, f := range export.AgentFiles {
+		b.WriteString(fmt.Sprintf("  [%d] %s\n", i+1, f.Name))
+	}
+	return b.String()
+}
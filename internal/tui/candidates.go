@@ -0,0 +1,465 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"promptgo/internal/ai"
+	"promptgo/internal/enhancer"
+	"promptgo/internal/experiment"
+	"promptgo/internal/feedback"
+)
+
+const candidateConcurrency = 2 // Max generation calls in flight per session
+
+type candidateResultMsg struct {
+	ch     <-chan enhancer.CandidateResult
+	result enhancer.CandidateResult
+}
+type candidatesDoneMsg struct {
+	ch <-chan enhancer.CandidateResult
+}
+
+// candidate tracks one tab in the candidates view
+type candidate struct {
+	spec      enhancer.CandidateSpec
+	result    *enhancer.Candidate
+	err       error
+	pending   bool
+	discarded bool
+	sections  []section
+}
+
+// section is a heading-delimited part of a prompt
+type section struct {
+	title string
+	body  string
+}
+
+// sectionRef points at a section of a candidate selected for merging
+type sectionRef struct {
+	candidate int
+	section   int
+}
+
+// WaitForCandidate returns a tea.Cmd that delivers the next finished candidate from ch
+func WaitForCandidate(ch <-chan enhancer.CandidateResult) tea.Cmd {
+	return func() tea.Msg {
+		result, ok := <-ch
+		if !ok {
+			return candidatesDoneMsg{ch: ch}
+		}
+		return candidateResultMsg{ch: ch, result: result}
+	}
+}
+
+// generateCandidates analyzes the task and asks its questions, then generates candidates from the answers
+func (m Model) generateCandidates() (tea.Model, tea.Cmd) {
+	if errMsg := m.validateInput(); errMsg != "" {
+		m.err = errMsg
+		return m, nil
+	}
+	if m.enhancer == nil {
+		m.err = "Generating candidates requires an Anthropic API key"
+		return m, nil
+	}
+	if m.overQuota() {
+		m.err = quotaMessage
+		return m, nil
+	}
+	var ok bool
+	if m, ok = m.reviewSensitive(actionCandidates); !ok {
+		return m, nil
+	}
+
+	return m.startAnalysis(actionCandidates)
+}
+
+// startCandidates starts parallel generation and switches to the candidates view
+func (m Model) startCandidates() (tea.Model, tea.Cmd) {
+	specs := enhancer.DefaultCandidateSpecs(m.input, m.enhancer.CandidateModels())
+	if m.candidateStats != nil {
+		stats := m.candidateStats()
+		specs = enhancer.RankCandidateSpecs(specs, func(key string) float64 { return stats[key].Rate() }, enhancer.MaxCandidates)
+	} else {
+		specs = enhancer.RankCandidateSpecs(specs, nil, enhancer.MaxCandidates)
+	}
+	m.candidates = make([]candidate, len(specs))
+	for i, spec := range specs {
+		m.candidates[i] = candidate{spec: spec, pending: true}
+	}
+	m.candidateIndex = 0
+	m.mergeSelection = nil
+	m.sectionCursor = 0
+	m.merging = false
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.candidateCancel = cancel
+	m.candidateCh = m.enhancer.GenerateCandidates(ctx, m.input, m.taskType, m.qa, specs, candidateConcurrency)
+
+	m.state = stateCandidates
+	m.err = ""
+	m.renderCandidate()
+
+	return m, WaitForCandidate(m.candidateCh)
+}
+
+// handleCandidateResult stores a finished candidate and waits for the next one
+func (m Model) handleCandidateResult(msg candidateResultMsg) (tea.Model, tea.Cmd) {
+	if r := msg.result.Candidate; r != nil {
+		m.addUsage(r.Model, r.InputTokens, r.OutputTokens)
+	}
+	// Ignore results from a generation the user already resolved
+	if msg.ch != m.candidateCh {
+		return m, nil
+	}
+
+	c := &m.candidates[msg.result.Index]
+	c.pending = false
+	c.result = msg.result.Candidate
+	c.err = msg.result.Err
+	if c.result != nil {
+		c.sections = splitSections(c.result.Prompt)
+	}
+	m.renderCandidate()
+
+	return m, WaitForCandidate(m.candidateCh)
+}
+
+// updateCandidates handles candidates view updates
+func (m Model) updateCandidates(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case "tab", "right", "l":
+		m.moveCandidate(1)
+		return m, nil
+
+	case "shift+tab", "left", "h":
+		m.moveCandidate(-1)
+		return m, nil
+
+	case "enter":
+		if len(m.mergeSelection) > 0 {
+			return m.mergeCandidates()
+		}
+		return m.pickCandidate()
+
+	case "x":
+		// Discard the current candidate
+		m.candidates[m.candidateIndex].discarded = true
+		m.dropMergeSelection(m.candidateIndex)
+		m.moveCandidate(1)
+		return m, nil
+
+	case "d":
+		// Compare with another finished candidate
+		return m.diffCandidates()
+
+	case "m":
+		// Toggle section selection for merging
+		if m.candidates[m.candidateIndex].result != nil {
+			m.merging = !m.merging
+			m.sectionCursor = 0
+			m.renderCandidate()
+		}
+		return m, nil
+
+	case "esc":
+		// Abandon all candidates
+		m.stopCandidates()
+		m.state = stateInput
+		return m, nil
+
+	case "q":
+		m.stopCandidates()
+		return m, tea.Quit
+	}
+
+	if m.merging {
+		sections := m.candidates[m.candidateIndex].sections
+		switch msg.String() {
+		case "up", "k":
+			if m.sectionCursor > 0 {
+				m.sectionCursor--
+			}
+			m.renderCandidate()
+			return m, nil
+		case "down", "j":
+			if m.sectionCursor < len(sections)-1 {
+				m.sectionCursor++
+			}
+			m.renderCandidate()
+			return m, nil
+		case " ":
+			m.toggleMergeSelection(sectionRef{candidate: m.candidateIndex, section: m.sectionCursor})
+			m.renderCandidate()
+			return m, nil
+		}
+	}
+
+	// Delegate to viewport for scrolling
+	m.resultViewport, cmd = m.resultViewport.Update(msg)
+	return m, cmd
+}
+
+// diffCandidates compares the current candidate with the next finished one; the others can be
+// stepped through in the diff view
+func (m Model) diffCandidates() (tea.Model, tea.Cmd) {
+	var sources []diffSource
+	from, to := -1, -1
+	n := len(m.candidates)
+	for step := range n {
+		c := m.candidates[(m.candidateIndex+step)%n]
+		if c.result == nil || c.discarded {
+			continue
+		}
+		switch {
+		case step == 0:
+			from = len(sources)
+		case to < 0:
+			to = len(sources)
+		}
+		sources = append(sources, diffSource{label: c.spec.Label, prompt: c.result.Prompt})
+	}
+	if from < 0 || to < 0 {
+		m.err = "Need two finished candidates to compare"
+		return m, nil
+	}
+	return m.showDiff(sources, from, to)
+}
+
+// moveCandidate moves to the next tab in direction dir that hasn't been discarded
+func (m *Model) moveCandidate(dir int) {
+	n := len(m.candidates)
+	for step := 1; step <= n; step++ {
+		i := ((m.candidateIndex+dir*step)%n + n) % n
+		if !m.candidates[i].discarded {
+			m.candidateIndex = i
+			break
+		}
+	}
+	m.merging = false
+	m.sectionCursor = 0
+	m.err = ""
+	m.renderCandidate()
+}
+
+// toggleMergeSelection adds or removes a section from the merge, keeping selection order
+func (m *Model) toggleMergeSelection(ref sectionRef) {
+	for i, sel := range m.mergeSelection {
+		if sel == ref {
+			m.mergeSelection = append(m.mergeSelection[:i], m.mergeSelection[i+1:]...)
+			return
+		}
+	}
+	m.mergeSelection = append(m.mergeSelection, ref)
+}
+
+// dropMergeSelection removes every selected section belonging to candidate i
+func (m *Model) dropMergeSelection(i int) {
+	kept := m.mergeSelection[:0]
+	for _, sel := range m.mergeSelection {
+		if sel.candidate != i {
+			kept = append(kept, sel)
+		}
+	}
+	m.mergeSelection = kept
+}
+
+// pickCandidate makes the current candidate the result and cancels the rest
+func (m Model) pickCandidate() (tea.Model, tea.Cmd) {
+	c := m.candidates[m.candidateIndex]
+	if c.result == nil || c.discarded {
+		return m, nil
+	}
+
+	m.recordPreference(m.candidateIndex, nil)
+	m.stopCandidates()
+	m.showCandidateResult(c.result.Prompt, c.result.Model, c.result.PromptVersion,
+		fmt.Sprintf("Picked the %s candidate. Use [f] to keep refining it.", c.spec.Label))
+
+	return m, nil
+}
+
+// mergeCandidates joins the selected sections, in the order they were selected, into the result
+func (m Model) mergeCandidates() (tea.Model, tea.Cmd) {
+	var parts []string
+	var contributors []int
+	seen := make(map[int]bool)
+	for _, sel := range m.mergeSelection {
+		parts = append(parts, m.candidates[sel.candidate].sections[sel.section].body)
+		if !seen[sel.candidate] {
+			seen[sel.candidate] = true
+			contributors = append(contributors, sel.candidate)
+		}
+	}
+
+	m.recordPreference(-1, contributors)
+	m.stopCandidates()
+	m.showCandidateResult(strings.Join(parts, "\n\n"), "", "",
+		fmt.Sprintf("Merged %d sections from %d candidates.", len(parts), len(contributors)))
+
+	return m, nil
+}
+
+// showCandidateResult moves the chosen prompt into the result view as the first revision
+func (m *Model) showCandidateResult(prompt, model, version, tip string) {
+	m.state = stateResult
+	m.revisions = []ai.Revision{{Prompt: prompt, Model: model, PromptVersion: version}}
+	m.tip = tip
+	m.showRevision(0)
+	m.track(experiment.EventGenerated)
+	m.candidates = nil
+	m.mergeSelection = nil
+	m.merging = false
+}
+
+// stopCandidates cancels any generation still in flight
+func (m *Model) stopCandidates() {
+	if m.candidateCancel != nil {
+		m.candidateCancel()
+		m.candidateCancel = nil
+	}
+	m.candidateCh = nil
+}
+
+// recordPreference stores which candidates the user chose, the one at winner or those merged, so
+// settings that win more often are offered first. Candidates that never finished weren't seen.
+func (m *Model) recordPreference(winner int, merged []int) {
+	e := feedback.Entry{Time: time.Now().UTC()}
+	for i, c := range m.candidates {
+		if c.result == nil {
+			continue
+		}
+		e.Candidates = append(e.Candidates, feedback.CandidateChoice{
+			Setting:   c.spec.Key(),
+			Chosen:    i == winner || slices.Contains(merged, i),
+			Discarded: c.discarded,
+		})
+	}
+	if m.recordFeedback != nil && len(e.Candidates) > 1 {
+		m.recordFeedback(e)
+	}
+}
+
+// renderCandidate fills the viewport with the current tab's prompt or section list
+func (m *Model) renderCandidate() {
+	c := m.candidates[m.candidateIndex]
+
+	var content string
+	switch {
+	case c.pending:
+		content = SubtitleStyle().Render("Generating...")
+	case c.err != nil:
+		content = ErrorStyle().Render("❌ " + c.err.Error())
+	case m.merging:
+		var b strings.Builder
+		for i, sec := range c.sections {
+			mark := "[ ]"
+			for n, sel := range m.mergeSelection {
+				if sel == (sectionRef{candidate: m.candidateIndex, section: i}) {
+					mark = fmt.Sprintf("[%d]", n+1)
+				}
+			}
+			line := fmt.Sprintf("%s %s (%d lines)", mark, sec.title, strings.Count(sec.body, "\n")+1)
+			b.WriteString(FieldLabelStyle(i == m.sectionCursor).Render(line))
+			b.WriteString("\n")
+		}
+		content = b.String()
+	default:
+		content = c.result.Prompt
+	}
+
+	m.resultViewport.Width = m.contentWidth
+	m.resultViewport.SetContent(content)
+	m.resultViewport.GotoTop()
+}
+
+// candidateTab renders the tab label with length and cost
+func candidateTab(c candidate) string {
+	switch {
+	case c.discarded:
+		return c.spec.Label + " · discarded"
+	case c.pending:
+		return c.spec.Label + " · generating..."
+	case c.err != nil:
+		return c.spec.Label + " · failed"
+	}
+
+	cost := "$?"
+	if c.result.CostKnown {
+		cost = fmt.Sprintf("$%.4f", c.result.Cost)
+	}
+	return fmt.Sprintf("%s · %d chars · %s", c.spec.Label, len(c.result.Prompt), cost)
+}
+
+// viewCandidates renders the candidates view
+func (m Model) viewCandidates() string {
+	var b strings.Builder
+
+	// Title
+	b.WriteString(TitleStyle().Render("🐹 PromptGo - Candidates"))
+	b.WriteString("\n\n")
+
+	// Tabs
+	tabs := make([]string, len(m.candidates))
+	for i, c := range m.candidates {
+		tabs[i] = TabStyle(i == m.candidateIndex).Render(candidateTab(c))
+	}
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, tabs...))
+	b.WriteString("\n")
+
+	// Viewport with the current candidate
+	b.WriteString(ContainerStyle().Render(m.resultViewport.View()))
+	b.WriteString("\n\n")
+
+	if m.err != "" {
+		b.WriteString(ErrorStyle().Render("❌ " + m.err))
+		b.WriteString("\n\n")
+	}
+
+	// Help
+	switch {
+	case m.merging:
+		b.WriteString(HelpStyle().Render("[↑/↓] Section   [Space] Select   [Tab] Next candidate   [Enter] Merge selected   [m] Back to prompt"))
+	case len(m.mergeSelection) > 0:
+		b.WriteString(HelpStyle().Render(fmt.Sprintf("[Tab] Next candidate   [m] Pick sections   [Enter] Merge %d sections   [x] Discard   [Esc] Cancel", len(m.mergeSelection))))
+	default:
+		b.WriteString(HelpStyle().Render("[Tab] Next candidate   [Enter] Pick   [d] Compare   [m] Pick sections to merge   [x] Discard   [Esc] Cancel"))
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// splitSections splits a markdown prompt at its headings
+func splitSections(prompt string) []section {
+	var sections []section
+	var current []string
+	title := "Introduction"
+
+	flush := func() {
+		body := strings.TrimSpace(strings.Join(current, "\n"))
+		if body != "" {
+			sections = append(sections, section{title: title, body: body})
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(prompt, "\n") {
+		if strings.HasPrefix(line, "#") {
+			flush()
+			title = strings.TrimSpace(strings.TrimLeft(line, "#"))
+		}
+		current = append(current, line)
+	}
+	flush()
+
+	return sections
+}
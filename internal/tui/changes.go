@@ -0,0 +1,105 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"promptgo/internal/project"
+)
+
+// maxPasteLines bounds the pasted diff or test output
+const maxPasteLines = 10000
+
+// WithChanges attaches a diff, commits or test failures that are sent along with the task
+func (m Model) WithChanges(c *project.Changes) Model {
+	m.changes = c
+	return m
+}
+
+// openChangesPaste opens a full-screen textarea for pasting a diff, git log or go test output
+func (m Model) openChangesPaste() (tea.Model, tea.Cmd) {
+	paste := textarea.New()
+	paste.Placeholder = "Paste git diff, git log or go test output..."
+	paste.MaxHeight = maxPasteLines
+	paste.CharLimit = 0
+	paste.ShowLineNumbers = false
+	paste.FocusedStyle.CursorLine = CursorStyle()
+	paste.Cursor.Style = CursorStyle()
+	paste.SetWidth(m.width - 4)
+	paste.SetHeight(m.height - 8)
+
+	m.blurAll()
+	m.changesInput = paste
+	m.state = stateChanges
+	m.err = ""
+
+	return m, m.changesInput.Focus()
+}
+
+// updateChanges handles the paste view
+func (m Model) updateChanges(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.Type {
+	case tea.KeyCtrlS:
+		// Parse and attach; saving nothing removes attached changes
+		text := m.changesInput.Value()
+		if strings.TrimSpace(text) == "" {
+			m.changes = nil
+			return m.closeChangesPaste()
+		}
+		c := project.ParseChanges(text)
+		if c.Empty() {
+			m.err = "No diff, commits or test failures found"
+			return m, nil
+		}
+		m.changes = c
+		return m.closeChangesPaste()
+
+	case tea.KeyEsc:
+		return m.closeChangesPaste()
+	}
+
+	m.changesInput, cmd = m.changesInput.Update(msg)
+	return m, cmd
+}
+
+// closeChangesPaste returns to the input view with focus where it was
+func (m Model) closeChangesPaste() (tea.Model, tea.Cmd) {
+	m.changesInput.Blur()
+	m.state = stateInput
+	m.err = ""
+
+	switch m.focused {
+	case fieldDetails:
+		m.detailsInput.Focus()
+	case fieldSecretWord:
+		m.secretInput.Focus()
+	default:
+		m.taskInput.Focus()
+	}
+	return m, nil
+}
+
+// viewChanges renders the paste view
+func (m Model) viewChanges() string {
+	var b strings.Builder
+
+	b.WriteString(TitleStyle().Render("🐹 PromptGo - Changes"))
+	b.WriteString("\n")
+	b.WriteString(SubtitleStyle().Render("Failing tests, panics and changed files guide bugfix and refactoring prompts"))
+	b.WriteString("\n\n")
+	b.WriteString(m.changesInput.View())
+	b.WriteString("\n\n")
+
+	if m.err != "" {
+		b.WriteString(ErrorStyle().Render("❌ " + m.err))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(HelpStyle().Render("[Ctrl+S] Attach (empty to remove)   [Esc] Cancel"))
+	b.WriteString("\n")
+
+	return b.String()
+}
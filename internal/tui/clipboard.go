@@ -3,8 +3,6 @@ package tui
 import (
 	"encoding/base64"
 	"fmt"
-	"os"
-	"path/filepath"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -37,23 +35,6 @@ func CopyToClipboard(content string) tea.Cmd {
 	}
 }
 
-// SaveToFile saves content to a file in the current directory
-func SaveToFile(content string) tea.Cmd {
-	return func() tea.Msg {
-		// Generate filename with timestamp
-		filename := fmt.Sprintf("prompt_%d.txt", time.Now().Unix())
-
-		// Save to current directory
-		path := filepath.Join(".", filename)
-		err := os.WriteFile(path, []byte(content), 0644)
-		if err != nil {
-			return saveErrorMsg{err: err}
-		}
-
-		return saveSuccessMsg{path: path}
-	}
-}
-
 // ShowCopyFeedback returns a tea.Cmd that shows feedback for 2 seconds
 func ShowCopyFeedback() tea.Cmd {
 	return tea.Tick(2*time.Second, func(t time.Time) tea.Msg {
@@ -0,0 +1,288 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"promptgo/internal/ai"
+	"promptgo/internal/diff"
+)
+
+type diffLayout int
+
+const (
+	layoutUnified diffLayout = iota
+	layoutSplit
+)
+
+// diffSource is a prompt the diff view can compare, with the label shown in its header
+type diffSource struct {
+	label  string
+	prompt string
+}
+
+// diffRow is one row of a rendered diff, holding the styled old and/or new line
+type diffRow struct {
+	old, new       string
+	hasOld, hasNew bool
+	equal          bool
+}
+
+// openDiff switches to the diff view comparing the previous revision with the current one
+func (m Model) openDiff() (tea.Model, tea.Cmd) {
+	if len(m.revisions) < 2 {
+		m.err = "Need at least two revisions to compare"
+		return m, nil
+	}
+
+	from, to := m.revisionIndex-1, m.revisionIndex
+	if from < 0 {
+		from, to = 0, 1
+	}
+	return m.showDiff(m.revisionSources(), from, to)
+}
+
+// showDiff switches to the diff view comparing sources[from] with sources[to]
+func (m Model) showDiff(sources []diffSource, from, to int) (tea.Model, tea.Cmd) {
+	m.diffSources = sources
+	m.diffFrom, m.diffTo = from, to
+	m.diffReturn = m.state
+	m.state = stateDiff
+	m.err = ""
+	m.renderDiff()
+	m.diffViewport.GotoTop()
+
+	return m, nil
+}
+
+// closeDiff returns to the screen the diff was opened from, re-flowed in case the window was resized meanwhile
+func (m Model) closeDiff() (tea.Model, tea.Cmd) {
+	m.state = m.diffReturn
+	switch m.state {
+	case stateResult:
+		m.setResultContent()
+	case stateCandidates:
+		m.renderCandidate()
+	}
+	return m, nil
+}
+
+// revisionSources returns the session's revisions as diff sources
+func (m Model) revisionSources() []diffSource {
+	sources := make([]diffSource, len(m.revisions))
+	for i, rev := range m.revisions {
+		sources[i] = diffSource{label: revisionLabel(rev, i), prompt: rev.Prompt}
+	}
+	return sources
+}
+
+// updateDiff handles diff view updates
+func (m Model) updateDiff(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case "[":
+		// Move the base prompt back
+		if m.diffFrom > 0 {
+			m.diffFrom--
+			m.renderDiff()
+		}
+		return m, nil
+
+	case "]":
+		// Move the base prompt forward
+		if m.diffFrom < len(m.diffSources)-1 {
+			m.diffFrom++
+			m.renderDiff()
+		}
+		return m, nil
+
+	case "{":
+		// Move the compared prompt back
+		if m.diffTo > 0 {
+			m.diffTo--
+			m.renderDiff()
+		}
+		return m, nil
+
+	case "}":
+		// Move the compared prompt forward
+		if m.diffTo < len(m.diffSources)-1 {
+			m.diffTo++
+			m.renderDiff()
+		}
+		return m, nil
+
+	case "u":
+		// Toggle unified and split layouts
+		if m.diffLayout == layoutUnified {
+			m.diffLayout = layoutSplit
+		} else {
+			m.diffLayout = layoutUnified
+		}
+		m.renderDiff()
+		return m, nil
+
+	case "w":
+		// Toggle word-level highlighting
+		m.diffWords = !m.diffWords
+		m.renderDiff()
+		return m, nil
+
+	case "d", "esc":
+		return m.closeDiff()
+
+	case "q":
+		m.stopCandidates()
+		return m, tea.Quit
+	}
+
+	// Delegate to viewport for scrolling
+	m.diffViewport, cmd = m.diffViewport.Update(msg)
+	return m, cmd
+}
+
+// renderDiff renders the selected prompts into the diff viewport
+func (m *Model) renderDiff() {
+	rows := buildDiffRows(m.diffSources[m.diffFrom].prompt, m.diffSources[m.diffTo].prompt, m.diffWords)
+
+	var content string
+	if m.diffLayout == layoutSplit {
+		content = renderSplitDiff(rows, m.diffViewport.Width)
+	} else {
+		content = renderUnifiedDiff(rows)
+	}
+
+	m.diffViewport.SetContent(content)
+}
+
+// buildDiffRows pairs deleted and inserted lines so changed lines sit next to each other
+func buildDiffRows(from, to string, wordLevel bool) []diffRow {
+	var rows []diffRow
+	var deleted, inserted []string
+
+	flush := func() {
+		for i := 0; i < len(deleted) || i < len(inserted); i++ {
+			switch {
+			case i < len(deleted) && i < len(inserted):
+				row := diffRow{hasOld: true, hasNew: true}
+				if wordLevel {
+					row.old, row.new = renderWordDiff(deleted[i], inserted[i])
+				} else {
+					row.old = DiffDeleteStyle().Render(deleted[i])
+					row.new = DiffInsertStyle().Render(inserted[i])
+				}
+				rows = append(rows, row)
+			case i < len(deleted):
+				rows = append(rows, diffRow{old: DiffDeleteStyle().Render(deleted[i]), hasOld: true})
+			default:
+				rows = append(rows, diffRow{new: DiffInsertStyle().Render(inserted[i]), hasNew: true})
+			}
+		}
+		deleted, inserted = nil, nil
+	}
+
+	for _, e := range diff.Lines(from, to) {
+		switch e.Op {
+		case diff.Delete:
+			deleted = append(deleted, e.Text)
+		case diff.Insert:
+			inserted = append(inserted, e.Text)
+		default:
+			flush()
+			rows = append(rows, diffRow{old: e.Text, new: e.Text, hasOld: true, hasNew: true, equal: true})
+		}
+	}
+	flush()
+
+	return rows
+}
+
+// renderWordDiff highlights the words that differ between a changed pair of lines
+func renderWordDiff(oldLine, newLine string) (string, string) {
+	var oldB, newB strings.Builder
+	for _, e := range diff.Words(oldLine, newLine) {
+		switch e.Op {
+		case diff.Delete:
+			oldB.WriteString(DiffDeleteWordStyle().Render(e.Text))
+		case diff.Insert:
+			newB.WriteString(DiffInsertWordStyle().Render(e.Text))
+		default:
+			oldB.WriteString(DiffDeleteStyle().Render(e.Text))
+			newB.WriteString(DiffInsertStyle().Render(e.Text))
+		}
+	}
+	return oldB.String(), newB.String()
+}
+
+// renderUnifiedDiff renders rows one below the other with +/- markers
+func renderUnifiedDiff(rows []diffRow) string {
+	var b strings.Builder
+	for _, row := range rows {
+		if row.equal {
+			b.WriteString("  " + row.old + "\n")
+			continue
+		}
+		if row.hasOld {
+			b.WriteString(DiffDeleteStyle().Render("- ") + row.old + "\n")
+		}
+		if row.hasNew {
+			b.WriteString(DiffInsertStyle().Render("+ ") + row.new + "\n")
+		}
+	}
+	return b.String()
+}
+
+// renderSplitDiff renders rows as two columns, old on the left and new on the right
+func renderSplitDiff(rows []diffRow, width int) string {
+	separator := HelpStyle().Render(" │ ")
+	colWidth := (width - lipgloss.Width(separator)) / 2
+	if colWidth < 10 {
+		colWidth = 10
+	}
+	col := lipgloss.NewStyle().Width(colWidth)
+
+	var b strings.Builder
+	for _, row := range rows {
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, col.Render(row.old), separator, col.Render(row.new)))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// revisionLabel describes the revision at index i for the diff header
+func revisionLabel(rev ai.Revision, i int) string {
+	label := fmt.Sprintf("Revision %d", i+1)
+	switch {
+	case rev.HumanEdited:
+		label += " (human-edited)"
+	case rev.Model != "":
+		label += " (" + rev.Model + ")"
+	}
+	return label
+}
+
+// viewDiff renders the diff view
+func (m Model) viewDiff() string {
+	var b strings.Builder
+
+	// Title
+	b.WriteString(TitleStyle().Render("🐹 PromptGo - Compare"))
+	b.WriteString("\n")
+	b.WriteString(DiffDeleteStyle().Render(m.diffSources[m.diffFrom].label))
+	b.WriteString(SubtitleStyle().Render(" → "))
+	b.WriteString(DiffInsertStyle().Render(m.diffSources[m.diffTo].label))
+	b.WriteString("\n\n")
+
+	// Viewport with the diff
+	b.WriteString(ContainerStyle().Render(m.diffViewport.View()))
+	b.WriteString("\n\n")
+
+	// Help
+	b.WriteString(HelpStyle().Render("[ ] Base prompt   { } Compared prompt   [u] Unified/split   [w] Word diff   [d] Back   [q] Quit"))
+	b.WriteString("\n")
+
+	return b.String()
+}
@@ -0,0 +1,270 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"promptgo/internal/ai"
+	"promptgo/internal/draft"
+)
+
+// autosaveInterval is how often the draft is saved while the user works
+const autosaveInterval = 3 * time.Second
+
+// autosaveMsg saves the draft
+type autosaveMsg struct{}
+
+// draftPollMsg checks whether a refinement started before a reconnect has finished
+type draftPollMsg struct{}
+
+// WithDrafts autosaves the session as user's draft and offers to resume a saved one
+func (m Model) WithDrafts(store *draft.Store, user string) Model {
+	m.drafts = store
+	m.draftUser = user
+	if d, ok := store.Get(user); ok && !d.Empty() {
+		m.resumeOffer = &d
+	}
+	return m
+}
+
+// autosaveTick schedules the next autosave; nil when drafts aren't saved
+func (m Model) autosaveTick() tea.Cmd {
+	if m.drafts == nil {
+		return nil
+	}
+	return tea.Tick(autosaveInterval, func(time.Time) tea.Msg { return autosaveMsg{} })
+}
+
+// snapshot captures the session as a draft
+func (m Model) snapshot() draft.Draft {
+	d := draft.Draft{
+		Task:       m.taskInput.Value(),
+		Details:    m.detailsInput.Value(),
+		SecretWord: m.secretInput.Value(),
+		Focused:    int(m.focused),
+		Stack:      m.stack,
+	}
+	if ws := m.currentWorkspace(); ws != nil {
+		d.Workspace = ws.ID
+	}
+	if len(m.revisions) > 0 {
+		input := m.input
+		d.Input = &input
+		d.TaskType = m.taskType
+		d.QA = m.qa
+		d.Revisions = m.revisions
+		d.RevisionIndex = m.revisionIndex
+		d.Tip = m.tip
+		d.Result = m.state != stateInput
+	}
+	if m.generating {
+		d.Pending = m.pending
+	}
+	return d
+}
+
+// autosave saves the draft. It's skipped while the resume offer is open so the saved draft isn't
+// replaced by an empty one, and while refining because the refinement saves its own result.
+func (m *Model) autosave() {
+	if m.drafts == nil || m.resumeOffer != nil || m.generating {
+		return
+	}
+	m.saveDraft()
+}
+
+// saveDraft saves the draft now
+func (m *Model) saveDraft() {
+	if err := m.drafts.Save(m.draftUser, m.snapshot()); err != nil {
+		m.err = "Failed to autosave: " + err.Error()
+	}
+}
+
+// refineCmd wraps a refinement so its tokens are counted and its result reaches the draft
+// even when the session disconnects before it finishes
+func (m Model) refineCmd(cmd tea.Cmd, feedback string) (Model, tea.Cmd) {
+	m.pending = &draft.Pending{Feedback: feedback, Started: time.Now().UTC()}
+	if m.drafts != nil {
+		m.saveDraft()
+	}
+
+	record := m.addUsage
+	store, user := m.drafts, m.draftUser
+	return m, func() tea.Msg {
+		msg := cmd()
+		if msg, ok := msg.(refineSuccessMsg); ok {
+			record(msg.output.Model, msg.output.InputTokens, msg.output.OutputTokens)
+		}
+		if store == nil {
+			return msg
+		}
+		_ = store.Update(user, func(d *draft.Draft) {
+			if d.Pending == nil {
+				return
+			}
+			d.Pending = nil
+			switch msg := msg.(type) {
+			case refineSuccessMsg:
+				d.Revisions = append(d.Revisions, refinedRevision(msg))
+				d.RevisionIndex = len(d.Revisions) - 1
+				d.Tip = msg.output.Tip
+				d.Result = true
+			case refineErrorMsg:
+				// A refinement the user abandoned didn't fail
+				if !errors.Is(msg.err, context.Canceled) {
+					d.Error = msg.err.Error()
+				}
+			}
+		})
+		return msg
+	}
+}
+
+// refinedRevision is the revision a successful refinement produced
+func refinedRevision(msg refineSuccessMsg) ai.Revision {
+	return ai.Revision{
+		Prompt:        msg.output.EnhancedPrompt,
+		Feedback:      msg.feedback,
+		Model:         msg.output.Model,
+		PromptVersion: msg.output.PromptVersion,
+	}
+}
+
+// updateResumeOffer handles the choice to resume or discard the saved draft
+func (m Model) updateResumeOffer(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "r", "enter":
+		return m.resumeDraft()
+
+	case "n", "esc":
+		m.resumeOffer = nil
+		if err := m.drafts.Delete(m.draftUser); err != nil {
+			m.err = "Failed to discard the draft: " + err.Error()
+		}
+	}
+	return m, nil
+}
+
+// resumeDraft restores the saved draft
+func (m Model) resumeDraft() (tea.Model, tea.Cmd) {
+	d := *m.resumeOffer
+	m.resumeOffer = nil
+	m.forgetRedactions()
+
+	m.taskInput.SetValue(d.Task)
+	m.detailsInput.SetValue(d.Details)
+	m.secretInput.SetValue(d.SecretWord)
+	m.stack = d.Stack
+	for i, ws := range m.workspaces {
+		if ws.ID == d.Workspace {
+			m.selectWorkspace(i)
+		}
+	}
+	m.blurAll()
+	m.focused = focusedField(d.Focused)
+	switch m.focused {
+	case fieldDetails:
+		m.detailsInput.Focus()
+	case fieldSecretWord:
+		m.secretInput.Focus()
+	default:
+		m.focused = fieldTask
+		m.taskInput.Focus()
+	}
+
+	if d.Error != "" {
+		m.err = "The refinement you started failed: " + d.Error
+	}
+	if len(d.Revisions) == 0 || d.Input == nil {
+		return m, nil
+	}
+
+	m.input = *d.Input
+	m.taskType = d.TaskType
+	m.qa = d.QA
+	m.revisions = d.Revisions
+	m.tip = d.Tip
+	m.showRevision(d.RevisionIndex)
+	if d.Result || d.Pending != nil {
+		m.state = stateResult
+	}
+
+	// Wait for a refinement still running from the previous connection
+	if d.Pending != nil {
+		if time.Since(d.Pending.Started) > refineTimeout {
+			m.err = "The refinement you started didn't finish; press [f] to try again"
+			return m, nil
+		}
+		m.generating = true
+		m.pending = d.Pending
+		return m, pollDraft()
+	}
+	return m, nil
+}
+
+// pollDraft schedules the next check for a refinement started before a reconnect
+func pollDraft() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return draftPollMsg{} })
+}
+
+// checkPendingDraft shows the refinement started before a reconnect once it has finished
+func (m Model) checkPendingDraft() (tea.Model, tea.Cmd) {
+	if !m.generating {
+		return m, nil
+	}
+	d, ok := m.drafts.Get(m.draftUser)
+	if ok && d.Pending != nil && time.Since(d.Pending.Started) <= refineTimeout {
+		return m, pollDraft()
+	}
+
+	m.generating = false
+	m.pending = nil
+	switch {
+	case !ok || d.Pending != nil:
+		m.err = "The refinement you started didn't finish; press [f] to try again"
+	case d.Error != "":
+		m.err = "The refinement you started failed: " + d.Error
+	case len(d.Revisions) > len(m.revisions):
+		m.revisions = d.Revisions
+		m.tip = d.Tip
+		m.showRevision(d.RevisionIndex)
+	}
+	return m, nil
+}
+
+// viewResumeOffer renders the offer to resume the saved draft
+func (m Model) viewResumeOffer() string {
+	var b strings.Builder
+	d := m.resumeOffer
+
+	title := strings.TrimSpace(strings.SplitN(strings.TrimSpace(d.Task), "\n", 2)[0])
+	if title == "" {
+		title = "untitled"
+	}
+	b.WriteString(FieldLabelStyle(true).Render("📝 You have an unfinished draft"))
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf("  %q, saved %s ago\n", truncateRunes(title, 60), time.Since(d.Saved).Round(time.Second)))
+	switch {
+	case d.Pending != nil:
+		b.WriteString("  A refinement was still running when you disconnected\n")
+	case len(d.Revisions) > 0:
+		b.WriteString(fmt.Sprintf("  With a generated prompt (%d revision(s))\n", len(d.Revisions)))
+	}
+	b.WriteString("\n")
+	b.WriteString(HelpStyle().Render("[r] Resume   [n] Discard"))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// truncateRunes shortens s to n runes
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
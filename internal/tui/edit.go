@@ -0,0 +1,143 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"promptgo/internal/ai"
+)
+
+const (
+	maxEditorLines = 5000 // Generated prompts are long, well beyond textarea's default
+	maxUndoSteps   = 200
+)
+
+// openEditor loads the current prompt into a full-screen textarea
+func (m Model) openEditor() (tea.Model, tea.Cmd) {
+	editor := textarea.New()
+	editor.MaxHeight = maxEditorLines
+	editor.CharLimit = 0
+	editor.ShowLineNumbers = true
+	editor.FocusedStyle.CursorLine = CursorStyle()
+	editor.Cursor.Style = CursorStyle()
+	editor.SetWidth(m.width - 4)
+	editor.SetHeight(m.height - 6)
+	editor.SetValue(m.enhancedPrompt)
+
+	m.editor = editor
+	m.undoStack = nil
+	m.redoStack = nil
+	m.lastEditWasRune = false
+	m.state = stateEdit
+	m.err = ""
+
+	return m, m.editor.Focus()
+}
+
+// updateEdit handles editor updates
+func (m Model) updateEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.Type {
+	case tea.KeyCtrlS:
+		// Save the edit as a new revision
+		return m.saveEdit()
+
+	case tea.KeyEsc:
+		// Discard the edit
+		m.editor.Blur()
+		m.state = stateResult
+		return m, nil
+
+	case tea.KeyCtrlZ:
+		m.undoEdit()
+		return m, nil
+
+	case tea.KeyCtrlY:
+		m.redoEdit()
+		return m, nil
+	}
+
+	before := m.editor.Value()
+	m.editor, cmd = m.editor.Update(msg)
+
+	if m.editor.Value() != before {
+		// Typing a word is one undo step; anything else starts a new one
+		isRune := msg.Type == tea.KeyRunes && !msg.Paste && strings.TrimSpace(string(msg.Runes)) != ""
+		if !isRune || !m.lastEditWasRune {
+			m.pushUndo(before)
+		}
+		m.lastEditWasRune = isRune
+		m.redoStack = nil
+	}
+
+	return m, cmd
+}
+
+// pushUndo records a snapshot to return to, dropping the oldest beyond the limit
+func (m *Model) pushUndo(value string) {
+	m.undoStack = append(m.undoStack, value)
+	if len(m.undoStack) > maxUndoSteps {
+		m.undoStack = m.undoStack[1:]
+	}
+}
+
+// undoEdit restores the previous snapshot
+func (m *Model) undoEdit() {
+	if len(m.undoStack) == 0 {
+		return
+	}
+	last := len(m.undoStack) - 1
+	m.redoStack = append(m.redoStack, m.editor.Value())
+	m.editor.SetValue(m.undoStack[last])
+	m.undoStack = m.undoStack[:last]
+	m.lastEditWasRune = false
+}
+
+// redoEdit re-applies a snapshot removed by undo
+func (m *Model) redoEdit() {
+	if len(m.redoStack) == 0 {
+		return
+	}
+	last := len(m.redoStack) - 1
+	m.undoStack = append(m.undoStack, m.editor.Value())
+	m.editor.SetValue(m.redoStack[last])
+	m.redoStack = m.redoStack[:last]
+	m.lastEditWasRune = false
+}
+
+// saveEdit stores the edited text as a human-edited revision and shows it
+func (m Model) saveEdit() (tea.Model, tea.Cmd) {
+	m.editor.Blur()
+	m.state = stateResult
+
+	edited := m.editor.Value()
+	if edited == m.enhancedPrompt {
+		return m, nil
+	}
+
+	// Restored values are redacted again so refinement doesn't send them
+	m.revisions = append(m.revisions, ai.Revision{
+		Prompt:      m.redactions.Apply(edited),
+		HumanEdited: true,
+	})
+	m.tip = "Saved your edits as a new revision. Copy, save and refine now use the edited text."
+	m.showRevision(len(m.revisions) - 1)
+
+	return m, nil
+}
+
+// viewEdit renders the full-screen editor
+func (m Model) viewEdit() string {
+	var b strings.Builder
+
+	b.WriteString(TitleStyle().Render("🐹 PromptGo - Edit Prompt"))
+	b.WriteString("\n\n")
+	b.WriteString(m.editor.View())
+	b.WriteString("\n\n")
+	b.WriteString(HelpStyle().Render("[Ctrl+S] Save as revision   [Ctrl+Z] Undo   [Ctrl+Y] Redo   [Esc] Discard"))
+	b.WriteString("\n")
+
+	return b.String()
+}
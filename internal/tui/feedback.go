@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"promptgo/internal/experiment"
+	"promptgo/internal/feedback"
+	"promptgo/internal/history"
+)
+
+// WithFeedback enables rating prompts; record stores each entry and fills in the user
+func (m Model) WithFeedback(record func(feedback.Entry)) Model {
+	m.recordFeedback = record
+	return m
+}
+
+// WithCandidateStats orders generated candidates by how often users chose their settings before
+func (m Model) WithCandidateStats(stats func() map[string]feedback.SettingStats) Model {
+	m.candidateStats = stats
+	return m
+}
+
+// openRating rates the prompt on screen and asks for an optional comment
+func (m Model) openRating(r feedback.Rating) (tea.Model, tea.Cmd) {
+	event := experiment.EventThumbsUp
+	if r == feedback.RatingDown {
+		event = experiment.EventThumbsDown
+	}
+	m.track(event)
+
+	if m.recordFeedback == nil {
+		return m.showFeedbackThanks()
+	}
+	m.rating = r
+	m.commentInput.SetValue("")
+	return m, m.commentInput.Focus()
+}
+
+// updateRating handles the optional comment after a rating
+func (m Model) updateRating(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyEnter:
+		// Esc still keeps the rating, just without a comment
+		entry := feedback.Entry{Rating: m.rating}
+		if msg.Type == tea.KeyEnter {
+			entry.Comment = strings.TrimSpace(m.commentInput.Value())
+		}
+		m.rating = ""
+		m.commentInput.Blur()
+		m.submitFeedback(entry)
+		return m.showFeedbackThanks()
+	}
+
+	m.commentInput, cmd = m.commentInput.Update(msg)
+	return m, cmd
+}
+
+// updateGateQuestion handles the answer to whether the agent respected the secret word gate
+func (m Model) updateGateQuestion(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "n":
+		respected := msg.String() == "y"
+		m.askingGate = false
+		m.submitFeedback(feedback.Entry{GateRespected: &respected})
+		return m.showFeedbackThanks()
+
+	case "esc":
+		m.askingGate = false
+	}
+	return m, nil
+}
+
+// submitFeedback records an entry for the revision on screen, keeping it with the history entry too
+func (m Model) submitFeedback(e feedback.Entry) {
+	if m.recordFeedback == nil {
+		return
+	}
+	e.Time = time.Now().UTC()
+	e.PromptID = feedback.PromptID(m.enhancedPrompt)
+	if m.revisionIndex < len(m.revisions) {
+		rev := m.revisions[m.revisionIndex]
+		e.PromptVersion = rev.PromptVersion
+		e.Model = rev.Model
+	}
+	m.recordFeedback(e)
+
+	if m.history == nil || m.historyID == "" {
+		return
+	}
+	err := m.history.Update(m.historyUser, m.historyID, func(h *history.Entry) {
+		if e.Rating != "" {
+			h.Rating, h.Comment = e.Rating, e.Comment
+		}
+		if e.GateRespected != nil {
+			h.GateRespected = e.GateRespected
+		}
+	})
+	if err != nil {
+		log.Printf("Failed to save feedback to history: %v", err)
+	}
+}
+
+// showFeedbackThanks briefly confirms feedback in the status bar
+func (m Model) showFeedbackThanks() (tea.Model, tea.Cmd) {
+	m.saveFeedback = "✓ Thanks for the feedback"
+	return m, tea.Tick(3*time.Second, func(t time.Time) tea.Msg {
+		return hideSaveFeedbackMsg{}
+	})
+}
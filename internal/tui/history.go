@@ -0,0 +1,238 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"promptgo/internal/export"
+	"promptgo/internal/history"
+	"promptgo/internal/share"
+)
+
+// WithHistory keeps every prompt the session generates in user's history and lets them reopen it
+func (m Model) WithHistory(store *history.Store, user string) Model {
+	m.history = store
+	m.historyUser = user
+	return m
+}
+
+// recordHistory saves the prompt on screen to the history whenever its revisions change. A
+// different first revision is a new prompt, which gets an entry of its own.
+func (m *Model) recordHistory() {
+	if m.history == nil || len(m.revisions) == 0 {
+		return
+	}
+	first, last := m.revisions[0].Prompt, m.revisions[len(m.revisions)-1].Prompt
+	if first != m.historyFirst {
+		m.historyID = ""
+	} else if len(m.revisions) == m.historyCount && last == m.historyLast {
+		return
+	}
+
+	e, err := m.history.Save(history.Entry{
+		ID:        m.historyID,
+		User:      m.historyUser,
+		Title:     export.DefaultTitle(m.input.Task),
+		Input:     m.input,
+		TaskType:  m.taskType,
+		QA:        m.qa,
+		Revisions: slices.Clone(m.revisions),
+	})
+	if err != nil {
+		m.err = "Failed to save to history: " + err.Error()
+		return
+	}
+	m.historyID = e.ID
+	m.historyFirst, m.historyCount, m.historyLast = first, len(m.revisions), last
+}
+
+// openHistory lists the user's past prompts
+func (m Model) openHistory() (tea.Model, tea.Cmd) {
+	if m.history == nil {
+		m.err = "History isn't available"
+		return m, nil
+	}
+	m.historyItems = m.history.List(m.historyUser)
+	if len(m.historyItems) == 0 {
+		m.err = "No prompts in your history yet"
+		return m, nil
+	}
+
+	m.blurAll()
+	m.historyCursor = 0
+	m.historyReturn = m.state
+	m.historyNote = ""
+	m.state = stateHistory
+	m.err = ""
+	return m, nil
+}
+
+// updateHistory handles the history view
+func (m Model) updateHistory(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.historyCursor > 0 {
+			m.historyCursor--
+		}
+
+	case "down", "j":
+		if m.historyCursor < len(m.historyItems)-1 {
+			m.historyCursor++
+		}
+
+	case "enter":
+		return m.reopen(m.historyItems[m.historyCursor])
+
+	case "x":
+		return m.revokeHistoryShares(m.historyItems[m.historyCursor])
+
+	case "d":
+		return m.diffHistory(m.historyItems[m.historyCursor])
+
+	case "esc":
+		return m.closeHistory()
+
+	case "q":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// liveShares returns the IDs of an entry's shares that can still be viewed
+func (m Model) liveShares(e history.Entry) []string {
+	if m.shares == nil {
+		return nil
+	}
+	var ids []string
+	for _, id := range e.Shares {
+		if _, err := m.shares.Get(id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// revokeHistoryShares revokes every live share created from a history entry
+func (m Model) revokeHistoryShares(e history.Entry) (tea.Model, tea.Cmd) {
+	m.err, m.historyNote = "", ""
+	ids := m.liveShares(e)
+	if len(ids) == 0 {
+		m.historyNote = "This prompt has no live shares"
+		return m, nil
+	}
+
+	for _, id := range ids {
+		// A share that's gone already needs no revoking
+		if err := m.shares.Revoke(id, m.shareOwner); err != nil && !errors.Is(err, share.ErrNotFound) {
+			m.err = fmt.Sprintf("Failed to revoke %s: %v", id, err)
+			return m, nil
+		}
+	}
+	m.historyNote = fmt.Sprintf("✓ Revoked %d share(s) of %q", len(ids), e.Title)
+	return m, nil
+}
+
+// diffHistory compares a history entry's latest revision with the prompt on screen. The
+// entry's other revisions and the session's can be stepped through as well.
+func (m Model) diffHistory(e history.Entry) (tea.Model, tea.Cmd) {
+	var sources []diffSource
+	for i, rev := range e.Revisions {
+		sources = append(sources, diffSource{label: truncateRunes(e.Title, 30) + ": " + revisionLabel(rev, i), prompt: rev.Prompt})
+	}
+	from := len(sources) - 1
+
+	if len(m.revisions) == 0 || e.ID == m.historyID {
+		// Nothing else on screen, compare the entry with itself over time
+		if len(sources) < 2 {
+			m.err = "This prompt has a single revision and there's no other prompt on screen to compare it with"
+			return m, nil
+		}
+		return m.showDiff(sources, 0, from)
+	}
+
+	for i, rev := range m.revisions {
+		sources = append(sources, diffSource{label: "On screen: " + revisionLabel(rev, i), prompt: rev.Prompt})
+	}
+	return m.showDiff(sources, from, len(e.Revisions)+m.revisionIndex)
+}
+
+// closeHistory returns to the screen the history was opened from
+func (m Model) closeHistory() (tea.Model, tea.Cmd) {
+	m.state = m.historyReturn
+	if m.state == stateInput {
+		m.focused = fieldTask
+		return m, m.taskInput.Focus()
+	}
+	m.setResultContent()
+	return m, nil
+}
+
+// reopen shows a history entry as the result, where refining it adds to the same entry
+func (m Model) reopen(e history.Entry) (tea.Model, tea.Cmd) {
+	m.cancelGeneration()
+	m.stopCandidates()
+	m.pending = nil
+	m.forgetRedactions()
+
+	m.input = e.Input
+	m.taskType = e.TaskType
+	m.qa = e.QA
+	m.revisions = slices.Clone(e.Revisions)
+	m.tip = fmt.Sprintf("Reopened from your history, first generated on %s. Use [f] to keep refining it.", e.Created.Local().Format("2006-01-02"))
+	m.historyID = e.ID
+	m.historyFirst, m.historyCount, m.historyLast = e.Revisions[0].Prompt, len(e.Revisions), e.Revisions[len(e.Revisions)-1].Prompt
+
+	m.state = stateResult
+	m.err = ""
+	m.showRevision(len(m.revisions) - 1)
+
+	// By now the prompt has usually been tried, so ask how the agent handled the gate
+	if m.recordFeedback != nil && e.GateRespected == nil {
+		m.askingGate = true
+	}
+	return m, nil
+}
+
+// historyLine summarizes an entry for the history list
+func historyLine(e history.Entry) string {
+	version := e.PromptVersion
+	if version == "" {
+		version = "(offline)"
+	}
+	return fmt.Sprintf("📄 %s · %s · %s · %d revision(s)", truncateRunes(e.Title, 40), e.Updated.Local().Format("2006-01-02 15:04"), version, len(e.Revisions))
+}
+
+// viewHistory renders the user's past prompts
+func (m Model) viewHistory() string {
+	var b strings.Builder
+
+	b.WriteString(TitleStyle().Render("🐹 PromptGo - History"))
+	b.WriteString("\n\n")
+
+	for i, e := range m.historyItems {
+		line := historyLine(e)
+		if n := len(m.liveShares(e)); n > 0 {
+			line += fmt.Sprintf(" · 🔗 %d shared", n)
+		}
+		b.WriteString(FieldLabelStyle(i == m.historyCursor).Render(line))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if m.historyNote != "" {
+		b.WriteString(SubtitleStyle().Render(m.historyNote))
+		b.WriteString("\n\n")
+	}
+	if m.err != "" {
+		b.WriteString(ErrorStyle().Render("❌ " + m.err))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(HelpStyle().Render("[↑/↓] Select   [Enter] Reopen   [d] Compare with prompt on screen   [x] Revoke shares   [Esc] Back"))
+	b.WriteString("\n")
+
+	return b.String()
+}
@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// RenderMarkdown renders markdown for the terminal, word-wrapped to width and using the
+// renderer's color profile and background. It falls back to the raw text if rendering fails.
+func RenderMarkdown(markdown string, width int, r *lipgloss.Renderer) string {
+	style := "dark"
+	if !r.HasDarkBackground() {
+		style = "light"
+	}
+
+	tr, err := glamour.NewTermRenderer(
+		glamour.WithStandardStyle(style),
+		glamour.WithColorProfile(r.ColorProfile()),
+		glamour.WithWordWrap(width),
+	)
+	if err != nil {
+		return markdown
+	}
+
+	out, err := tr.Render(markdown)
+	if err != nil {
+		return markdown
+	}
+
+	return strings.Trim(out, "\n")
+}
+
+// setResultContent fills the result viewport with the current prompt, rendered or raw,
+// then re-applies the outline, search highlights and gutter to the new lines
+func (m *Model) setResultContent() {
+	m.resultViewport.Width = m.contentWidth
+	if m.showOutline {
+		m.resultViewport.Width -= outlineWidth + 1
+	}
+
+	content := m.enhancedPrompt
+	if !m.rawView {
+		width := m.resultViewport.Width
+		if m.showLineNumbers {
+			width -= gutterWidth + 1
+		}
+		content = RenderMarkdown(content, width, m.renderer)
+	}
+
+	m.resultLines = strings.Split(content, "\n")
+	m.findMatches()
+	m.buildOutline()
+	m.refreshResultViewport()
+}
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -9,7 +10,21 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"promptgo/internal/ai"
+	"promptgo/internal/collab"
+	"promptgo/internal/draft"
 	"promptgo/internal/enhancer"
+	"promptgo/internal/experiment"
+	"promptgo/internal/export"
+	"promptgo/internal/feedback"
+	"promptgo/internal/history"
+	"promptgo/internal/project"
+	"promptgo/internal/redact"
+	"promptgo/internal/secret"
+	"promptgo/internal/share"
+	"promptgo/internal/templates"
+	"promptgo/internal/workspace"
 )
 
 type hideSaveFeedbackMsg struct{}
@@ -19,7 +34,14 @@ type appState int
 
 const (
 	stateInput appState = iota
+	stateQuestions
 	stateResult
+	stateDiff
+	stateCandidates
+	stateEdit
+	stateChanges
+	stateLibrary
+	stateHistory
 )
 
 type focusedField int
@@ -39,22 +61,170 @@ type Model struct {
 	taskInput    textarea.Model
 	detailsInput textarea.Model
 	secretInput  textinput.Model
+	project      *project.Context // Attached project context, nil if none
+	changes      *project.Changes // Attached diff, commits and test failures, nil if none
+	changesInput textarea.Model   // Paste area for changes (changesView)
+	stack        string           // Chosen stack pack ID, empty to detect it
+	budget       *enhancer.Budget // Token budget of the input, nil without an enhancer
+	budgetFor    budgetInputs     // What the budget was planned for
+	secretGen    *secret.Generator
+	rememberWord func(string) // Saves the secret word as the user's default, nil to not remember
+
+	// Sensitive data review (inputView)
+	sensitive       []redact.Finding // Findings awaiting a redact or keep decision
+	sensitiveAction pendingAction
+	reviewedText    string          // Input the current decision applies to
+	redactions      *redact.Mapping // Values replaced before sending, nil when nothing was redacted
+	blockSecrets    bool            // Policy: credentials can't be kept
+
+	// Task analysis (questionsView)
+	taskType      ai.TaskType // Empty until the task is analyzed
+	questions     []string
+	answers       []string
+	questionIndex int
+	answerInput   textinput.Model
+	qa            map[string]string  // Answered questions the prompt was generated from
+	nextAction    pendingAction      // What to generate once the questions are answered
+	gen           int                // Bumped per generation so results of an abandoned one are dropped
+	genCancel     context.CancelFunc // Cancels the analysis, generation or refinement in flight
 
 	// Result data (resultView)
 	enhancedPrompt string
 	tip            string
 	resultViewport viewport.Model
+	renderer       *lipgloss.Renderer // Session renderer, used for its color profile
+	rawView        bool               // Show the raw markdown instead of rendering it
+
+	// Search and section navigation (resultView)
+	resultLines     []string // Displayed lines before highlighting and gutter
+	searchInput     textinput.Model
+	searching       bool // Search input is open
+	searchQuery     string
+	matches         []searchMatch
+	matchIndex      int
+	outline         []heading
+	outlineCursor   int
+	showOutline     bool
+	showLineNumbers bool
+
+	// Inline editing (editView)
+	editor          textarea.Model
+	undoStack       []string
+	redoStack       []string
+	lastEditWasRune bool // Consecutive typed characters share one undo step
+
+	// Refinement (resultView)
+	enhancer      *enhancer.Enhancer // nil when no API key is configured, which disables generation
+	input         enhancer.Input
+	revisions     []ai.Revision
+	revisionIndex int
+	feedbackInput textinput.Model
+	refining      bool // Feedback input is open
+	generating    bool // Waiting for the model
+
+	// Prompt comparison (diffView)
+	diffViewport viewport.Model
+	diffSources  []diffSource // Prompts that can be compared, revisions, history entries or candidates
+	diffFrom     int          // Index of the base prompt in diffSources
+	diffTo       int          // Index of the compared prompt in diffSources
+	diffReturn   appState     // Screen the diff was opened from
+	diffLayout   diffLayout
+	diffWords    bool
+
+	// Candidate selection (candidatesView)
+	candidates      []candidate
+	candidateIndex  int
+	candidateCancel context.CancelFunc
+	candidateCh     <-chan enhancer.CandidateResult
+	merging         bool
+	sectionCursor   int
+	mergeSelection  []sectionRef
+	candidateStats  func() map[string]feedback.SettingStats // Choices so far by candidate setting; nil when not tracked
 
 	// UI state
 	width        int
 	height       int
+	contentWidth int
 	err          string
 	copyFeedback bool
 	saveFeedback string
+
+	// Save dialog (resultView)
+	saving        bool
+	saveFormat    int    // Index into export.Exporters
+	saveConfirm   string // Existing file the next Enter overwrites
+	saveNameInput textinput.Model
+	keepDocument  func(export.Document) // Hands saves to the SSH server; nil when running locally
+
+	// Agent rule file export (resultView)
+	agentMenu       bool
+	agentFullPrompt bool // Export the whole prompt rather than its conventions sections
+
+	// System prompt experiments, nil when none are running
+	experiments *experiment.Session
+
+	// Prompt feedback (resultView)
+	recordFeedback func(feedback.Entry) // nil when feedback isn't collected
+	rating         feedback.Rating      // Rating awaiting its optional comment, empty otherwise
+	commentInput   textinput.Model
+	askingGate     bool // Secret word gate follow-up is open
+
+	// Team workspaces (inputView, libraryView)
+	workspaces      []Workspace
+	workspaceIndex  int                // Index into workspaces, -1 when working alone
+	defaultEnhancer *enhancer.Enhancer // Used outside workspaces without their own settings
+	library         *workspace.Library
+	author          string // Name shown on published prompts
+	libraryItems    []libraryItem
+	libraryCursor   int
+
+	// Prompt history (historyView), nil store when history isn't kept
+	history       *history.Store
+	historyUser   string
+	historyID     string // Entry the prompt on screen is saved as, empty before it is saved
+	historyFirst  string // First and last revisions and the number saved, to spot changes
+	historyLast   string
+	historyCount  int
+	historyItems  []history.Entry
+	historyCursor int
+	historyReturn appState // Screen the history was opened from
+	historyNote   string   // Result of the last action in the history view
+
+	// Prompt sharing (resultView)
+	shares       *share.Store // nil when sharing is unavailable
+	shareOwner   string
+	shareBaseURL string
+	sharing      bool // Share dialog is open
+	shareAccess  int  // Index into share.Accesses
+	shareExpiry  int  // Index into shareExpiries
+
+	// Server integration
+	recordUsage   func(model string, inputTokens, outputTokens int64)
+	quotaExceeded func() bool
+	notice        string    // Server notice shown as a banner
+	noticeAt      time.Time // When the notice's countdown ends, zero for none
+	noticeSeq     int       // Bumped per notice so ticks of a replaced countdown stop
+
+	// Draft autosave, nil when drafts aren't saved
+	drafts      *draft.Store
+	draftUser   string
+	resumeOffer *draft.Draft   // Saved draft the user hasn't resumed or discarded yet
+	pending     *draft.Pending // Refinement being waited for
+
+	// Pairing (inputView, resultView)
+	startPairing func() (*collab.Member, error) // nil when pairing is unavailable
+	pair         *collab.Member                 // nil when not pairing
+	pairSnap     collab.Snapshot                // Latest state, with the versions this session has seen
+	pairEvent    string                         // Last thing that happened in the pairing session
 }
 
-// NewModel creates a new TUI model
-func NewModel() Model {
+// NewModel creates a new TUI model. enh may be nil, in which case refinement is unavailable.
+// renderer should match the session's terminal; nil uses the default renderer.
+func NewModel(enh *enhancer.Enhancer, renderer *lipgloss.Renderer) Model {
+	if renderer == nil {
+		renderer = lipgloss.DefaultRenderer()
+	}
+
 	// Configure task textarea
 	task := textarea.New()
 	task.Placeholder = "Describe what you want to build..."
@@ -77,12 +247,45 @@ func NewModel() Model {
 	details.Cursor.Style = CursorStyle()
 
 	// Configure secret word input
-	secret := textinput.New()
-	secret.Placeholder = "your secret word"
-	secret.CharLimit = 50
-	secret.Width = 80
-	secret.Cursor.Style = CursorStyle()
-	secret.TextStyle = CursorStyle()
+	secretWord := textinput.New()
+	secretWord.Placeholder = "your secret word"
+	secretWord.CharLimit = 50
+	secretWord.Width = 80
+	secretWord.Cursor.Style = CursorStyle()
+	secretWord.TextStyle = CursorStyle()
+
+	// Configure refinement feedback input
+	feedback := textinput.New()
+	feedback.Placeholder = "make it shorter, add benchmarks, we use sqlc not gorm..."
+	feedback.CharLimit = 1000
+	feedback.Width = 80
+	feedback.Cursor.Style = CursorStyle()
+
+	// Configure question answer input
+	answer := textinput.New()
+	answer.Placeholder = "Your answer, or Enter to skip"
+	answer.CharLimit = 1000
+	answer.Width = 80
+	answer.Cursor.Style = CursorStyle()
+
+	// Configure result search input
+	search := textinput.New()
+	search.Prompt = "/"
+	search.CharLimit = 100
+	search.Cursor.Style = CursorStyle()
+
+	// Configure save dialog filename input
+	saveName := textinput.New()
+	saveName.CharLimit = 255
+	saveName.Width = 60
+	saveName.Cursor.Style = CursorStyle()
+
+	// Configure feedback comment input
+	comment := textinput.New()
+	comment.Placeholder = "What worked or didn't? (optional)"
+	comment.CharLimit = 1000
+	comment.Width = 60
+	comment.Cursor.Style = CursorStyle()
 
 	// Create viewport for results
 	vp := viewport.New(80, 20)
@@ -92,20 +295,52 @@ func NewModel() Model {
 		focused:        fieldTask,
 		taskInput:      task,
 		detailsInput:   details,
-		secretInput:    secret,
+		secretInput:    secretWord,
 		resultViewport: vp,
+		renderer:       renderer,
+		searchInput:    search,
+		diffViewport:   viewport.New(80, 20),
+		diffWords:      true,
+		enhancer:       enh,
+		answerInput:    answer,
+		feedbackInput:  feedback,
+		saveNameInput:  saveName,
+		commentInput:   comment,
 		width:          80,
 		height:         24,
+		contentWidth:   80,
 	}
 }
 
+// WithProjectContext attaches project context that is sent along with the task
+func (m Model) WithProjectContext(pc *project.Context) Model {
+	m.project = pc
+	return m
+}
+
 // Init initializes the model
 func (m Model) Init() tea.Cmd {
-	return textarea.Blink
+	return tea.Batch(textarea.Blink, m.autosaveTick(), m.waitForPairing())
 }
 
-// Update handles messages
+// Update handles messages, then replans the token budget when the input changed and shares the
+// user's edits when pairing
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	nm, ok := next.(Model)
+	if !ok {
+		return next, cmd
+	}
+	nm.planBudget()
+	nm.recordHistory()
+	if nm.pair != nil {
+		nm = nm.syncPairing()
+	}
+	return nm, cmd
+}
+
+// update handles messages for the current state
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
@@ -119,8 +354,22 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch m.state {
 		case stateInput:
 			return m.updateInput(msg)
+		case stateQuestions:
+			return m.updateQuestions(msg)
 		case stateResult:
 			return m.updateResult(msg)
+		case stateDiff:
+			return m.updateDiff(msg)
+		case stateCandidates:
+			return m.updateCandidates(msg)
+		case stateEdit:
+			return m.updateEdit(msg)
+		case stateChanges:
+			return m.updateChanges(msg)
+		case stateLibrary:
+			return m.updateLibrary(msg)
+		case stateHistory:
+			return m.updateHistory(msg)
 		}
 
 	case tea.WindowSizeMsg:
@@ -134,14 +383,38 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			contentWidth = maxContentWidth
 		}
 
+		m.contentWidth = contentWidth
+
 		// Update input fields
 		m.taskInput.SetWidth(contentWidth)
 		m.detailsInput.SetWidth(contentWidth)
 		m.secretInput.Width = contentWidth
+		m.answerInput.Width = contentWidth
+		m.feedbackInput.Width = contentWidth
+		m.commentInput.Width = contentWidth
+		if m.state == stateEdit {
+			m.editor.SetWidth(msg.Width - 4)
+			m.editor.SetHeight(msg.Height - 6)
+		}
+		if m.state == stateChanges {
+			m.changesInput.SetWidth(msg.Width - 4)
+			m.changesInput.SetHeight(msg.Height - 8)
+		}
 
 		// Update result viewport
 		m.resultViewport.Width = contentWidth
 		m.resultViewport.Height = msg.Height - 15 // Leave room for header/footer
+		if m.state == stateResult {
+			// Re-flow the rendered markdown to the new width
+			m.setResultContent()
+		}
+
+		// Update diff viewport, re-rendering since split columns depend on width
+		m.diffViewport.Width = contentWidth
+		m.diffViewport.Height = msg.Height - 10
+		if m.state == stateDiff {
+			m.renderDiff()
+		}
 
 		return m, nil
 
@@ -154,11 +427,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case saveSuccessMsg:
+		m.track(experiment.EventSave)
 		m.saveFeedback = fmt.Sprintf("✓ Saved to %s", msg.path)
 		return m, tea.Tick(3*time.Second, func(t time.Time) tea.Msg {
 			return hideSaveFeedbackMsg{}
 		})
 
+	case downloadReadyMsg:
+		// Stays up until the next save so the command can be copied
+		m.track(experiment.EventSave)
+		m.saveFeedback = "✓ Ready to download: " + msg.command
+		return m, nil
+
 	case saveErrorMsg:
 		m.saveFeedback = fmt.Sprintf("✗ Error: %v", msg.err)
 		return m, tea.Tick(3*time.Second, func(t time.Time) tea.Msg {
@@ -169,6 +449,72 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.saveFeedback = ""
 		return m, nil
 
+	case NoticeMsg:
+		return m.setNotice(msg.Text, msg.At)
+
+	case ShutdownMsg:
+		m.autosave()
+		return m.setNotice(shutdownMessage, msg.At)
+
+	case autosaveMsg:
+		m.autosave()
+		return m, m.autosaveTick()
+
+	case draftPollMsg:
+		return m.checkPendingDraft()
+
+	case pairingMsg:
+		m = m.applyPairing(msg.snap)
+		return m, m.waitForPairing()
+
+	case pairingEndedMsg:
+		m.pair = nil
+		return m, nil
+
+	case noticeTickMsg:
+		if msg.seq != m.noticeSeq || !time.Now().Before(m.noticeAt) {
+			return m, nil
+		}
+		return m, m.noticeTick()
+
+	case questionsMsg:
+		return m.handleQuestions(msg)
+
+	case generateSuccessMsg:
+		return m.handleGenerated(msg)
+
+	case generateErrorMsg:
+		return m.handleGenerateError(msg)
+
+	case refineSuccessMsg:
+		if msg.gen != m.gen {
+			return m, nil
+		}
+		m.generating = false
+		m.pending = nil
+		m.revisions = append(m.revisions, refinedRevision(msg))
+		m.tip = msg.output.Tip
+		m.showRevision(len(m.revisions) - 1)
+		return m, nil
+
+	case candidateResultMsg:
+		return m.handleCandidateResult(msg)
+
+	case candidatesDoneMsg:
+		if msg.ch == m.candidateCh {
+			m.stopCandidates()
+		}
+		return m, nil
+
+	case refineErrorMsg:
+		if msg.gen != m.gen {
+			return m, nil
+		}
+		m.generating = false
+		m.pending = nil
+		m.err = msg.err.Error()
+		return m, nil
+
 	case printAndExitMsg:
 		// Print the enhanced prompt to stdout, then quit
 		fmt.Println("\n" + strings.Repeat("=", 80))
@@ -186,6 +532,31 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m Model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
+	if m.resumeOffer != nil {
+		return m.updateResumeOffer(msg)
+	}
+	if len(m.sensitive) > 0 {
+		return m.updateSensitive(msg)
+	}
+	if m.generating {
+		// Typing is fine while the model works, but not sending the input again
+		switch msg.Type {
+		case tea.KeyEsc:
+			m.cancelGeneration()
+			return m, nil
+		case tea.KeyCtrlE, tea.KeyCtrlG:
+			return m, nil
+		}
+	}
+	if msg.Type == tea.KeyCtrlR {
+		// Start pairing, or pass the turn on or ask for it
+		return m.togglePairing()
+	}
+	if !m.myTurn() && msg.Type != tea.KeyTab && msg.Type != tea.KeyShiftTab {
+		m.err = m.notYourTurn()
+		return m, nil
+	}
+
 	switch msg.Type {
 	case tea.KeyTab:
 		// Cycle focus forward
@@ -222,6 +593,39 @@ func (m Model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case tea.KeyCtrlE:
 		// Trigger enhancement
 		return m.enhance()
+
+	case tea.KeyCtrlG:
+		// Generate several candidates to choose from
+		return m.generateCandidates()
+
+	case tea.KeyCtrlN:
+		// Generate a secret word
+		if m.secretGen != nil {
+			m.secretInput.SetValue(m.secretGen.Generate())
+			m.secretInput.CursorEnd()
+		}
+		return m, nil
+
+	case tea.KeyCtrlT:
+		// Cycle the stack pack: auto, then each pack in turn
+		m.stack = nextStack(m.stack)
+		return m, nil
+
+	case tea.KeyCtrlO:
+		// Paste a diff, git log or test output
+		return m.openChangesPaste()
+
+	case tea.KeyCtrlW:
+		// Switch workspace
+		return m.nextWorkspace()
+
+	case tea.KeyCtrlL:
+		// Browse the workspace's templates and published prompts
+		return m.openLibrary()
+
+	case tea.KeyCtrlP:
+		// Browse past prompts
+		return m.openHistory()
 	}
 
 	// Delegate to focused field
@@ -241,14 +645,92 @@ func (m Model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) updateResult(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
+	if len(m.sensitive) > 0 {
+		return m.updateSensitive(msg)
+	}
+	if m.refining {
+		return m.updateFeedback(msg)
+	}
+	if m.searching {
+		return m.updateSearch(msg)
+	}
+	if m.saving {
+		return m.updateSaveDialog(msg)
+	}
+	if m.agentMenu {
+		return m.updateAgentMenu(msg)
+	}
+	if m.sharing {
+		return m.updateShareDialog(msg)
+	}
+	if m.rating != "" {
+		return m.updateRating(msg)
+	}
+	if m.askingGate {
+		return m.updateGateQuestion(msg)
+	}
+	if m.showOutline {
+		// The outline takes arrow keys and enter while open, other keys fall through
+		switch msg.String() {
+		case "up", "k", "down", "j", "enter", "o", "esc":
+			return m.updateOutline(msg)
+		}
+	}
+
+	switch msg.String() {
+	case "ctrl+r":
+		// Pass the turn on or ask for it
+		if m.pair != nil {
+			return m.togglePairing()
+		}
+		return m, nil
+
+	case "f", "[", "]", "e", "r":
+		// These change the shared prompt
+		if !m.myTurn() {
+			m.err = m.notYourTurn()
+			return m, nil
+		}
+	}
+
 	switch msg.String() {
 	case "c":
 		// Copy to clipboard
+		m.track(experiment.EventCopy)
 		return m, CopyToClipboard(m.enhancedPrompt)
 
+	case "+":
+		// Thumbs up, with an optional comment
+		return m.openRating(feedback.RatingUp)
+
+	case "-":
+		// Thumbs down, with an optional comment
+		return m.openRating(feedback.RatingDown)
+
+	case "l":
+		// Share through a link or ID
+		return m.openShareDialog()
+
+	case "P":
+		// Publish to the workspace library
+		return m.publish()
+
+	case "g":
+		// Report whether the agent respected the secret word gate
+		if m.recordFeedback != nil {
+			m.askingGate = true
+		}
+		return m, nil
+
 	case "s":
-		// Save to file
-		return m, SaveToFile(m.enhancedPrompt)
+		// Pick a format and filename to save as
+		return m.openSaveDialog()
+
+	case "a":
+		// Save as an agent rule file
+		m.agentMenu = true
+		m.err = ""
+		return m, nil
 
 	case "p":
 		// Print to terminal and exit
@@ -256,17 +738,83 @@ func (m Model) updateResult(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return printAndExitMsg{}
 		}
 
+	case "f":
+		// Open the refinement feedback input
+		if m.generating {
+			return m, nil
+		}
+		if m.enhancer == nil {
+			m.err = "Refinement requires an Anthropic API key"
+			return m, nil
+		}
+		if m.overQuota() {
+			m.err = quotaMessage
+			return m, nil
+		}
+		m.refining = true
+		m.err = ""
+		m.feedbackInput.SetValue("")
+		return m, m.feedbackInput.Focus()
+
+	case "[":
+		// Step back to the previous revision
+		m.showRevision(m.revisionIndex - 1)
+		return m, nil
+
+	case "]":
+		// Step forward to the next revision
+		m.showRevision(m.revisionIndex + 1)
+		return m, nil
+
+	case "d":
+		// Compare revisions
+		return m.openDiff()
+
+	case "h":
+		// Browse past prompts
+		return m.openHistory()
+
+	case "e":
+		// Edit the prompt by hand
+		return m.openEditor()
+
+	case "v":
+		// Toggle rendered and raw markdown; exports always use the raw text
+		m.rawView = !m.rawView
+		m.setResultContent()
+		return m, nil
+
+	case "/":
+		// Search the prompt
+		m.searching = true
+		m.searchInput.SetValue(m.searchQuery)
+		return m, m.searchInput.Focus()
+
+	case "n":
+		// Next search match
+		m.jumpToMatch(m.matchIndex + 1)
+		return m, nil
+
+	case "N":
+		// Previous search match
+		m.jumpToMatch(m.matchIndex - 1)
+		return m, nil
+
+	case "o":
+		// Open the section outline
+		m.showOutline = true
+		m.setResultContent()
+		return m, nil
+
+	case "#":
+		// Toggle the line-number gutter
+		m.showLineNumbers = !m.showLineNumbers
+		m.setResultContent()
+		return m, nil
+
 	case "r":
 		// Reset to input view
-		m.state = stateInput
-		m.taskInput.SetValue("")
-		m.detailsInput.SetValue("")
-		m.secretInput.SetValue("")
-		m.focused = fieldTask
-		m.err = ""
-		m.copyFeedback = false
-		m.saveFeedback = ""
-		m.taskInput.Focus()
+		m.startOver()
 		return m, nil
 
 	case "q":
@@ -278,35 +826,194 @@ func (m Model) updateResult(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-// enhance triggers the enhancement process
-func (m Model) enhance() (tea.Model, tea.Cmd) {
-	// Validate
-	if strings.TrimSpace(m.taskInput.Value()) == "" {
-		m.err = "Task is required"
+// updateFeedback handles the refinement feedback input
+func (m Model) updateFeedback(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.refining = false
+		m.feedbackInput.Blur()
 		return m, nil
+
+	case tea.KeyEnter:
+		if strings.TrimSpace(m.feedbackInput.Value()) == "" {
+			return m, nil
+		}
+		m.refining = false
+		m.feedbackInput.Blur()
+		return m.refine()
 	}
-	if strings.TrimSpace(m.secretInput.Value()) == "" {
-		m.err = "Secret word is required"
+
+	m.feedbackInput, cmd = m.feedbackInput.Update(msg)
+	return m, cmd
+}
+
+// refine sends the feedback typed in the feedback input, once its sensitive values are reviewed
+func (m Model) refine() (tea.Model, tea.Cmd) {
+	var ok bool
+	if m, ok = m.reviewSensitive(actionRefine); !ok {
 		return m, nil
 	}
 
-	// Enhance
+	feedback := m.redactions.Apply(strings.TrimSpace(m.feedbackInput.Value()))
+	m.generating = true
+	m.gen++
+	m.track(experiment.EventRefine)
+	// Refine the revision on screen, replaying the revisions that led to it
+	return m.refineCmd(RefinePrompt(m.generationContext(), m.enhancer, m.input, m.taskType, m.qa, m.revisions[:m.revisionIndex+1], feedback, m.gen), feedback)
+}
+
+// startOver clears everything about the current prompt for a new one. A call to the model still
+// running is cancelled.
+func (m *Model) startOver() {
+	m.cancelGeneration()
+	m.stopCandidates()
+	m.pending = nil
+	m.state = stateInput
+	m.taskInput.SetValue("")
+	m.detailsInput.SetValue("")
+	// The secret word stays as the default for the next prompt
+	m.focused = fieldTask
+	m.err = ""
+	m.copyFeedback = false
+	m.saveFeedback = ""
+
+	m.changes = nil
+	m.forgetRedactions()
+
+	m.taskType = ""
+	m.questions = nil
+	m.answers = nil
+	m.qa = nil
+
+	m.input = enhancer.Input{}
+	m.revisions = nil
+	m.revisionIndex = 0
+	m.enhancedPrompt = ""
+	m.tip = ""
+	m.searchQuery = ""
+	m.matches = nil
+	m.showOutline = false
+
+	m.candidates = nil
+	m.mergeSelection = nil
+	m.merging = false
+
+	// The next prompt gets a history entry of its own
+	m.historyID = ""
+	m.historyFirst, m.historyLast, m.historyCount = "", "", 0
+
+	m.taskInput.Focus()
+	if m.drafts != nil {
+		// Replace the draft now so a refinement finishing later doesn't bring the old prompt back
+		m.saveDraft()
+	}
+}
+
+// validateInput returns an error message if the input fields are incomplete
+func (m Model) validateInput() string {
+	if strings.TrimSpace(m.taskInput.Value()) == "" {
+		return "Task is required"
+	}
+	if strings.TrimSpace(m.secretInput.Value()) == "" {
+		return "Secret word is required"
+	}
+	return ""
+}
+
+// currentInput collects the input fields into an enhancer.Input, with redacted values replaced
+func (m Model) currentInput() enhancer.Input {
+	input := m.rawInput()
+
+	// Redacted values never leave the session; placeholders are restored for display
+	input.Task = m.redactions.Apply(input.Task)
+	input.Details = m.redactions.Apply(input.Details)
+	input.ProjectContext = m.redactions.Apply(input.ProjectContext)
+	input.APIMap = m.redactions.Apply(input.APIMap)
+	input.ChangeContext = m.redactions.Apply(input.ChangeContext)
+	return input
+}
+
+// rawInput collects the input fields as typed, before redaction
+func (m Model) rawInput() enhancer.Input {
 	input := enhancer.Input{
 		Task:       m.taskInput.Value(),
 		Details:    m.detailsInput.Value(),
 		SecretWord: m.secretInput.Value(),
 	}
-	output := enhancer.Enhance(input)
+	if m.project != nil {
+		input.ProjectContext = m.project.Summary(project.SummaryLimit)
+		input.APIMap = m.project.APIMap(input.Task+" "+input.Details, project.APIMapLimit)
+	}
+	if m.changes != nil {
+		input.ChangeContext = m.changes.Summary(project.ChangesLimit)
+	}
+	input.Stack = m.currentStack().ID
+	input.AnalyzerVersion, input.GeneratorVersion = m.experiments.Versions()
+	if ws := m.currentWorkspace(); ws != nil {
+		input.HouseRules = ws.HouseRules
+	}
+	return input
+}
 
-	// Transition to result view
-	m.state = stateResult
-	m.enhancedPrompt = output.EnhancedPrompt
-	m.tip = output.Tip
-	m.resultViewport.SetContent(output.EnhancedPrompt)
-	m.resultViewport.GotoTop()
-	m.err = ""
+// enhance analyzes the task, asks its questions and then generates the prompt
+func (m Model) enhance() (tea.Model, tea.Cmd) {
+	// Validate
+	if errMsg := m.validateInput(); errMsg != "" {
+		m.err = errMsg
+		return m, nil
+	}
+	if m.enhancer == nil {
+		m.err = "Enhancement requires an Anthropic API key"
+		return m, nil
+	}
+	if m.overQuota() {
+		m.err = quotaMessage
+		return m, nil
+	}
+	var ok bool
+	if m, ok = m.reviewSensitive(actionEnhance); !ok {
+		return m, nil
+	}
 
-	return m, nil
+	return m.startAnalysis(actionEnhance)
+}
+
+// budgetInputs is everything the input's token budget depends on
+type budgetInputs struct {
+	enhancer  *enhancer.Enhancer
+	task      string
+	details   string
+	stack     string
+	project   *project.Context
+	changes   *project.Changes
+	workspace int
+	redact    *redact.Mapping
+}
+
+// planBudget replans the input's token budget when something it depends on changed, so it
+// isn't recomputed on every render
+func (m *Model) planBudget() {
+	inputs := budgetInputs{
+		enhancer:  m.enhancer,
+		task:      m.taskInput.Value(),
+		details:   m.detailsInput.Value(),
+		stack:     m.stack,
+		project:   m.project,
+		changes:   m.changes,
+		workspace: m.workspaceIndex,
+		redact:    m.redactions,
+	}
+	if inputs == m.budgetFor && (m.budget != nil || m.enhancer == nil) {
+		return
+	}
+	m.budgetFor = inputs
+	m.budget = nil
+	if m.enhancer != nil {
+		budget := m.enhancer.Budget(m.currentInput(), "", nil)
+		m.budget = &budget
+	}
 }
 
 // blurAll blurs all input fields
@@ -322,8 +1029,22 @@ func (m Model) View() string {
 	switch m.state {
 	case stateInput:
 		content = m.viewInput()
+	case stateQuestions:
+		content = m.viewQuestions()
 	case stateResult:
 		content = m.viewResult()
+	case stateDiff:
+		content = m.viewDiff()
+	case stateCandidates:
+		content = m.viewCandidates()
+	case stateEdit:
+		content = m.viewEdit()
+	case stateChanges:
+		content = m.viewChanges()
+	case stateLibrary:
+		content = m.viewLibrary()
+	case stateHistory:
+		content = m.viewHistory()
 	default:
 		return ""
 	}
@@ -341,6 +1062,42 @@ func (m Model) viewInput() string {
 	b.WriteString("\n")
 	b.WriteString(SubtitleStyle().Render("Stop letting AI write garbage Go code"))
 	b.WriteString("\n\n")
+	b.WriteString(m.viewNotice())
+	b.WriteString(m.viewPairing())
+
+	// Active workspace
+	if ws := m.currentWorkspace(); ws != nil {
+		b.WriteString(StatusBarStyle().Render("👥 Workspace: " + ws.Name + "   [Ctrl+W] switch   [Ctrl+L] library"))
+		b.WriteString("\n\n")
+	} else if len(m.workspaces) > 0 {
+		b.WriteString(SubtitleStyle().Render("👤 Personal   [Ctrl+W] switch workspace"))
+		b.WriteString("\n\n")
+	}
+
+	// Attached project context
+	if m.project != nil {
+		b.WriteString(StatusBarStyle().Render("📦 Project: " + m.project.Label()))
+		b.WriteString("\n\n")
+	}
+	if m.changes != nil {
+		b.WriteString(StatusBarStyle().Render("🔧 Changes: " + m.changes.Label()))
+		b.WriteString("\n\n")
+	}
+	stackLabel := m.currentStack().Name
+	if m.stack == "" {
+		stackLabel += " (auto)"
+	}
+	b.WriteString(SubtitleStyle().Render("🧰 Stack: " + stackLabel + "   [Ctrl+T] change"))
+	b.WriteString("\n")
+	if budget := m.budget; budget != nil {
+		style := SubtitleStyle()
+		if budget.Trimmed() {
+			style = TipStyle()
+		}
+		b.WriteString(style.Render("📊 Tokens: " + budget.String()))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
 
 	// Task field
 	b.WriteString(FieldLabelStyle(m.focused == fieldTask).Render("What do you want to build?"))
@@ -360,14 +1117,29 @@ func (m Model) viewInput() string {
 	b.WriteString(m.secretInput.View())
 	b.WriteString("\n\n")
 
+	if m.generating {
+		b.WriteString(SubtitleStyle().Render(m.generatingStatus()))
+		b.WriteString("\n\n")
+	}
+
 	// Error message
 	if m.err != "" {
 		b.WriteString(ErrorStyle().Render("❌ " + m.err))
 		b.WriteString("\n\n")
 	}
 
+	// The resume offer and sensitive data review replace the usual help
+	if m.resumeOffer != nil {
+		b.WriteString(m.viewResumeOffer())
+		return b.String()
+	}
+	if len(m.sensitive) > 0 {
+		b.WriteString(m.viewSensitive())
+		return b.String()
+	}
+
 	// Help
-	b.WriteString(HelpStyle().Render("[Tab] Next field   [Shift+Tab] Prev   [Ctrl+E] Enhance   [Ctrl+C] Quit"))
+	b.WriteString(HelpStyle().Render("[Tab] Next field   [Shift+Tab] Prev   [Ctrl+E] Enhance   [Ctrl+G] Candidates   [Ctrl+O] Changes   [Ctrl+P] History   [Ctrl+N] New secret word   [Ctrl+R] Pair   [Ctrl+C] Quit"))
 	b.WriteString("\n")
 
 	return b.String()
@@ -379,19 +1151,111 @@ func (m Model) viewResult() string {
 
 	// Title
 	b.WriteString(TitleStyle().Render("🐹 PromptGo - Enhanced Prompt"))
+	if len(m.revisions) > 1 {
+		label := fmt.Sprintf("Revision %d/%d", m.revisionIndex+1, len(m.revisions))
+		if m.revisions[m.revisionIndex].HumanEdited {
+			label += " · human-edited"
+		}
+		b.WriteString(SubtitleStyle().Render(label))
+	}
 	b.WriteString("\n\n")
+	b.WriteString(m.viewNotice())
+	b.WriteString(m.viewPairing())
 
-	// Viewport with enhanced prompt
-	b.WriteString(ContainerStyle().Render(m.resultViewport.View()))
-	b.WriteString("\n\n")
+	// Viewport with enhanced prompt, beside the outline when it's open
+	body := m.resultViewport.View()
+	if m.showOutline {
+		body = lipgloss.JoinHorizontal(lipgloss.Top, m.viewOutline(), body)
+	}
+	b.WriteString(ContainerStyle().Render(body))
+	b.WriteString("\n")
+	if status := m.searchStatus(); status != "" && !m.searching {
+		b.WriteString(SubtitleStyle().Render(status))
+	}
+	b.WriteString("\n")
 
 	// Tip
 	tipText := fmt.Sprintf("💡 %s", m.tip)
 	b.WriteString(TipStyle().Render(tipText))
 	b.WriteString("\n\n")
 
+	// Refinement feedback
+	if m.refining {
+		b.WriteString(FieldLabelStyle(true).Render("Feedback:"))
+		b.WriteString(" ")
+		b.WriteString(m.feedbackInput.View())
+		b.WriteString("\n\n")
+	}
+	if m.searching {
+		b.WriteString(m.searchInput.View())
+		b.WriteString("\n\n")
+	}
+	if m.saving {
+		b.WriteString(m.viewSaveDialog())
+		b.WriteString("\n")
+	}
+	if m.agentMenu {
+		b.WriteString(m.viewAgentMenu())
+		b.WriteString("\n")
+	}
+	if m.sharing {
+		b.WriteString(m.viewShareDialog())
+		b.WriteString("\n")
+	}
+	if m.rating != "" {
+		label := "👍 Comment:"
+		if m.rating == feedback.RatingDown {
+			label = "👎 Comment:"
+		}
+		b.WriteString(FieldLabelStyle(true).Render(label))
+		b.WriteString(" ")
+		b.WriteString(m.commentInput.View())
+		b.WriteString("\n\n")
+	}
+	if m.askingGate {
+		b.WriteString(FieldLabelStyle(true).Render("Did the agent respect the secret-word gate?"))
+		b.WriteString("\n\n")
+	}
+	if m.generating {
+		b.WriteString(SubtitleStyle().Render("Refining prompt..."))
+		b.WriteString("\n\n")
+	}
+
+	// Error message
+	if m.err != "" {
+		b.WriteString(ErrorStyle().Render("❌ " + m.err))
+		b.WriteString("\n\n")
+	}
+	if problem := secret.CheckGate(m.enhancedPrompt, m.input.SecretWord).Problem(); problem != "" {
+		b.WriteString(ErrorStyle().Render("⚠ Secret word gate: " + problem + " — [f] to refine or [e] to fix it"))
+		b.WriteString("\n\n")
+	}
+
 	// Help
-	b.WriteString(HelpStyle().Render("[p] Print & exit (copyable!)   [s] Save to file   [r] Start over   [q] Quit"))
+	switch {
+	case len(m.sensitive) > 0:
+		b.WriteString(m.viewSensitive())
+	case m.refining:
+		b.WriteString(HelpStyle().Render("[Enter] Refine   [Esc] Cancel"))
+	case m.searching:
+		b.WriteString(HelpStyle().Render("[Enter] Keep highlights   [Esc] Clear search"))
+	case m.saving:
+		b.WriteString(HelpStyle().Render("[Tab] Format   [Enter] Save   [Esc] Cancel"))
+	case m.agentMenu:
+		b.WriteString(HelpStyle().Render("[1-4] Save   [Tab] Conventions/full prompt   [Esc] Cancel"))
+	case m.sharing:
+		b.WriteString(HelpStyle().Render("[Tab] Who can see it   [x] Expiry   [Enter] Share   [Esc] Cancel"))
+	case m.rating != "":
+		b.WriteString(HelpStyle().Render("[Enter] Send   [Esc] Skip comment"))
+	case m.askingGate:
+		b.WriteString(HelpStyle().Render("[y] Yes   [n] No   [Esc] Cancel"))
+	case m.showOutline:
+		b.WriteString(HelpStyle().Render("[↑/↓] Section   [Enter] Jump   [o] Close outline"))
+	default:
+		b.WriteString(HelpStyle().Render("[/] Search   [n/N] Next/prev match   [o] Outline   [#] Line numbers"))
+		b.WriteString("\n")
+		b.WriteString(HelpStyle().Render("[p] Print & exit (copyable!)   [s] Save to file   [a] Agent rules   [l] Share   [P] Publish   [e] Edit   [f] Refine   [+/-] Rate   [g] Gate kept?   [ ] Revisions   [d] Diff   [h] History   [v] Raw/rendered   [r] Start over   [q] Quit"))
+	}
 	b.WriteString("\n")
 
 	// Feedback messages
@@ -406,3 +1270,63 @@ func (m Model) viewResult() string {
 
 	return b.String()
 }
+
+// WithSecretWords enables secret word generation and prefills the user's last word.
+// remember is called with the word whenever a prompt is generated; it may be nil.
+func (m Model) WithSecretWords(gen *secret.Generator, last string, remember func(string)) Model {
+	m.secretGen = gen
+	m.rememberWord = remember
+	if last != "" {
+		m.secretInput.SetValue(last)
+	} else if gen != nil {
+		m.secretInput.Placeholder = "your secret word, or Ctrl+N to generate one"
+	}
+	return m
+}
+
+// WithExperiments assigns the session's system prompt versions and tracks its outcomes
+func (m Model) WithExperiments(s *experiment.Session) Model {
+	m.experiments = s
+	return m
+}
+
+// track records an experiment outcome for the revision on screen
+func (m Model) track(event experiment.Event) {
+	var version string
+	if m.revisionIndex < len(m.revisions) {
+		version = m.revisions[m.revisionIndex].PromptVersion
+	}
+	m.experiments.Track(event, version)
+}
+
+// saveSecretWord remembers the entered secret word as the user's default
+func (m Model) saveSecretWord() {
+	if m.rememberWord != nil {
+		m.rememberWord(strings.TrimSpace(m.secretInput.Value()))
+	}
+}
+
+// currentStack returns the chosen stack pack, or the one detected from the task and project
+func (m Model) currentStack() templates.Stack {
+	if m.stack != "" {
+		return templates.StackByID(m.stack)
+	}
+	var manifests []string
+	if m.project != nil {
+		manifests = m.project.Manifests
+	}
+	return templates.DetectStack(m.taskInput.Value()+" "+m.detailsInput.Value(), manifests)
+}
+
+// nextStack cycles from auto-detection through each stack pack and back
+func nextStack(id string) string {
+	if id == "" {
+		return templates.Stacks[0].ID
+	}
+	for i, s := range templates.Stacks {
+		if s.ID == id && i+1 < len(templates.Stacks) {
+			return templates.Stacks[i+1].ID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,279 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"promptgo/internal/collab"
+)
+
+// pairingMsg delivers the latest state of the pairing session
+type pairingMsg struct {
+	snap collab.Snapshot
+}
+
+// pairingEndedMsg is sent once the session has left pairing
+type pairingEndedMsg struct{}
+
+// WithPairing lets the user start pairing with start, which is nil when pairing is unavailable.
+// member is non-nil when the session joined someone else's pairing session.
+func (m Model) WithPairing(start func() (*collab.Member, error), member *collab.Member) Model {
+	m.startPairing = start
+	if member != nil {
+		m = m.attachPairing(member)
+	}
+	return m
+}
+
+// WaitForPairing returns a tea.Cmd that delivers the next state of the pairing session
+func WaitForPairing(ch <-chan collab.Snapshot) tea.Cmd {
+	return func() tea.Msg {
+		snap, ok := <-ch
+		if !ok {
+			return pairingEndedMsg{}
+		}
+		return pairingMsg{snap: snap}
+	}
+}
+
+// waitForPairing waits for the next pairing update; nil when not pairing
+func (m Model) waitForPairing() tea.Cmd {
+	if m.pair == nil {
+		return nil
+	}
+	return WaitForPairing(m.pair.Updates())
+}
+
+// attachPairing starts following member's pairing session, taking on what it already has
+func (m Model) attachPairing(member *collab.Member) Model {
+	m.pair = member
+	m.pairSnap = collab.Snapshot{}
+	return m.applyPairing(member.Snapshot())
+}
+
+// togglePairing starts pairing, or once pairing passes the turn on or asks for it
+func (m Model) togglePairing() (tea.Model, tea.Cmd) {
+	if m.pair != nil {
+		m.pair.Turn()
+		return m, nil
+	}
+	if m.startPairing == nil {
+		m.err = "Pairing is only available when connected over SSH"
+		return m, nil
+	}
+
+	member, err := m.startPairing()
+	if err != nil {
+		m.err = "Failed to start pairing: " + err.Error()
+		return m, nil
+	}
+	m = m.attachPairing(member)
+	m.err = ""
+	return m, m.waitForPairing()
+}
+
+// myTurn reports whether the user may edit; always true when not pairing
+func (m Model) myTurn() bool {
+	return m.pair == nil || m.pairSnap.Turn == m.pair.ID()
+}
+
+// notYourTurn explains why an edit was refused
+func (m Model) notYourTurn() string {
+	name := "someone else"
+	if h := m.pairSnap.Holder(); h != nil {
+		name = h.Name
+	}
+	return fmt.Sprintf("It's %s's turn to edit; press [Ctrl+R] to ask for it", name)
+}
+
+// pairFields are the shared input fields
+var pairFields = []collab.Field{collab.FieldTask, collab.FieldDetails, collab.FieldSecretWord}
+
+// fieldValue returns the local value of a shared field
+func (m Model) fieldValue(f collab.Field) string {
+	switch f {
+	case collab.FieldTask:
+		return m.taskInput.Value()
+	case collab.FieldDetails:
+		return m.detailsInput.Value()
+	default:
+		return m.secretInput.Value()
+	}
+}
+
+// setField replaces the local value of a shared field
+func (m *Model) setField(f collab.Field, value string) {
+	switch f {
+	case collab.FieldTask:
+		m.taskInput.SetValue(value)
+	case collab.FieldDetails:
+		m.detailsInput.SetValue(value)
+	default:
+		m.secretInput.SetValue(value)
+	}
+}
+
+// localResult is the generated prompt to share, nil when there is none
+func (m Model) localResult() *collab.Result {
+	if len(m.revisions) == 0 {
+		return nil
+	}
+	return &collab.Result{Input: m.input, TaskType: m.taskType, QA: m.qa, Revisions: m.revisions, Index: m.revisionIndex, Tip: m.tip}
+}
+
+// sameResult reports whether two shared results show the same thing
+func sameResult(a, b *collab.Result) bool {
+	if a == nil || len(a.Revisions) == 0 || b == nil || len(b.Revisions) == 0 {
+		return (a == nil || len(a.Revisions) == 0) == (b == nil || len(b.Revisions) == 0)
+	}
+	n := len(a.Revisions)
+	return n == len(b.Revisions) && a.Index == b.Index && a.Tip == b.Tip && a.Revisions[n-1].Prompt == b.Revisions[n-1].Prompt
+}
+
+// syncPairing shares the user's edits after each update. Only the participant whose turn it is
+// can edit; an edit that races with someone else's is dropped in favour of theirs.
+func (m Model) syncPairing() Model {
+	m.pair.SetView(stateNames[m.state])
+	if !m.myTurn() {
+		return m
+	}
+
+	for _, f := range pairFields {
+		known := &m.pairSnap.Fields[f]
+		value := m.fieldValue(f)
+		if value == known.Value {
+			continue
+		}
+		version, err := m.pair.Edit(f, value, known.Version)
+		if err != nil {
+			m.refusedEdit(err)
+			m.setField(f, known.Value)
+			continue
+		}
+		known.Value = value
+		known.Version = version
+	}
+
+	local := m.localResult()
+	if sameResult(local, m.pairSnap.Result) {
+		return m
+	}
+	share := collab.Result{}
+	if local != nil {
+		share = *local
+	}
+	version, err := m.pair.SetResult(share, m.pairSnap.ResultVersion)
+	if err != nil {
+		// The newer result arrives with the next update and replaces this one
+		m.refusedEdit(err)
+		return m
+	}
+	share.Revisions = slices.Clone(share.Revisions)
+	m.pairSnap.Result = &share
+	m.pairSnap.ResultVersion = version
+	return m
+}
+
+// refusedEdit explains an edit the pairing session didn't accept
+func (m *Model) refusedEdit(err error) {
+	switch {
+	case errors.Is(err, collab.ErrNotYourTurn):
+		m.err = m.notYourTurn()
+	case errors.Is(err, collab.ErrConflict):
+		m.err = "Someone else changed this at the same time; showing their version"
+	default:
+		m.err = err.Error()
+	}
+}
+
+// applyPairing takes on the changes others made. Fields and results are only replaced by
+// newer versions, so an update sent before the user's own latest edit doesn't undo it.
+func (m Model) applyPairing(snap collab.Snapshot) Model {
+	fields := m.pairSnap.Fields
+	for _, f := range pairFields {
+		if snap.Fields[f].Version > fields[f].Version {
+			fields[f] = snap.Fields[f]
+			if m.fieldValue(f) != fields[f].Value {
+				m.setField(f, fields[f].Value)
+			}
+		}
+	}
+
+	result, resultVersion := m.pairSnap.Result, m.pairSnap.ResultVersion
+	if snap.ResultVersion > resultVersion {
+		result, resultVersion = snap.Result, snap.ResultVersion
+		if result == nil || len(result.Revisions) == 0 {
+			// Started over
+			m.revisions = nil
+			m.revisionIndex = 0
+			if m.state == stateResult {
+				m.state = stateInput
+			}
+		} else if !sameResult(result, m.localResult()) {
+			m.input = result.Input
+			m.taskType = result.TaskType
+			m.qa = result.QA
+			m.revisions = slices.Clone(result.Revisions)
+			m.tip = result.Tip
+			m.showRevision(result.Index)
+			if m.state == stateInput {
+				m.state = stateResult
+			}
+		}
+	}
+
+	if snap.Event != "" {
+		m.pairEvent = snap.Event
+	}
+	snap.Fields = fields
+	snap.Result, snap.ResultVersion = result, resultVersion
+	m.pairSnap = snap
+	return m
+}
+
+// viewPairing renders who is pairing and whose turn it is, or "" when not pairing
+func (m Model) viewPairing() string {
+	if m.pair == nil {
+		return ""
+	}
+	var b strings.Builder
+
+	var people []string
+	for _, p := range m.pairSnap.Participants {
+		name := p.Name
+		if p.ID == m.pair.ID() {
+			name += " (you)"
+		}
+		switch {
+		case p.ID == m.pairSnap.Turn && p.Typing():
+			name = "✏️  " + name + " typing…"
+		case p.ID == m.pairSnap.Turn:
+			name = "✏️  " + name
+		case p.WantsTurn:
+			name = "✋ " + name
+		}
+		if p.View != "" && p.View != "input" {
+			name += " [" + p.View + "]"
+		}
+		people = append(people, name)
+	}
+
+	code := m.pair.Code()
+	b.WriteString(StatusBarStyle().Render(fmt.Sprintf("👥 Pairing %s   %s", code, strings.Join(people, "  ·  "))))
+	b.WriteString("\n")
+	hint := "[Ctrl+R] Ask for the turn"
+	if m.myTurn() {
+		hint = "[Ctrl+R] Pass the turn"
+	}
+	line := hint + "   Teammates join with `ssh -t … join " + code + "`"
+	if m.pairEvent != "" {
+		line = m.pairEvent + "   " + line
+	}
+	b.WriteString(SubtitleStyle().Render(line))
+	b.WriteString("\n\n")
+
+	return b.String()
+}
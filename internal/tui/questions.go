@@ -0,0 +1,294 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"promptgo/internal/ai"
+	"promptgo/internal/enhancer"
+	"promptgo/internal/experiment"
+)
+
+// generateTimeout bounds the analysis and the generation of a prompt
+const generateTimeout = 2 * time.Minute
+
+// questionsMsg delivers the task analysis
+type questionsMsg struct {
+	gen    int
+	output *enhancer.QuestionsOutput
+}
+
+// generateSuccessMsg delivers the generated prompt
+type generateSuccessMsg struct {
+	gen    int
+	output *enhancer.Output
+}
+
+// generateErrorMsg reports a failed analysis or generation
+type generateErrorMsg struct {
+	gen int
+	err error
+}
+
+// AnalyzeTask returns a tea.Cmd that classifies the task and asks the model for context questions
+func AnalyzeTask(ctx context.Context, e *enhancer.Enhancer, input enhancer.Input, gen int) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(ctx, generateTimeout)
+		defer cancel()
+
+		output, err := e.GetQuestions(ctx, input)
+		if err != nil {
+			return generateErrorMsg{gen: gen, err: err}
+		}
+		return questionsMsg{gen: gen, output: output}
+	}
+}
+
+// GeneratePrompt returns a tea.Cmd that generates the prompt from the task and the user's answers
+func GeneratePrompt(ctx context.Context, e *enhancer.Enhancer, input enhancer.Input, taskType ai.TaskType, qa map[string]string, gen int) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(ctx, generateTimeout)
+		defer cancel()
+
+		output, err := e.GeneratePrompt(ctx, input, taskType, qa)
+		if err != nil {
+			return generateErrorMsg{gen: gen, err: err}
+		}
+		return generateSuccessMsg{gen: gen, output: output}
+	}
+}
+
+// startAnalysis sends the input for analysis; the questions come back as a questionsMsg and
+// action runs once they are answered
+func (m Model) startAnalysis(action pendingAction) (tea.Model, tea.Cmd) {
+	m.nextAction = action
+	m.input = m.currentInput()
+	m.saveSecretWord()
+	m.gen++
+	m.generating = true
+	m.taskType = ""
+	m.questions = nil
+	m.qa = nil
+	m.err = ""
+	return m, AnalyzeTask(m.generationContext(), m.enhancer, m.input, m.gen)
+}
+
+// generationContext returns the context for a new call to the model, cancelled by cancelGeneration
+func (m *Model) generationContext() context.Context {
+	if m.genCancel != nil {
+		m.genCancel()
+	}
+	var ctx context.Context
+	ctx, m.genCancel = context.WithCancel(context.Background())
+	return ctx
+}
+
+// cancelGeneration abandons the analysis, generation or refinement in flight: the call is
+// cancelled, and its result dropped if it arrives anyway
+func (m *Model) cancelGeneration() {
+	if m.genCancel != nil {
+		m.genCancel()
+		m.genCancel = nil
+	}
+	m.gen++
+	m.generating = false
+}
+
+// handleQuestions asks the analysis' questions, or generates straight away when there are none
+func (m Model) handleQuestions(msg questionsMsg) (tea.Model, tea.Cmd) {
+	// The analysis is paid for even when the user has moved on
+	m.addUsage(msg.output.Model, msg.output.InputTokens, msg.output.OutputTokens)
+	if msg.gen != m.gen {
+		return m, nil
+	}
+	m.generating = false
+	m.taskType = msg.output.TaskType
+	m.questions = msg.output.Questions
+	m.answers = make([]string, len(m.questions))
+	if len(m.questions) == 0 {
+		return m.finishQuestions()
+	}
+
+	m.state = stateQuestions
+	m.blurAll()
+	m.showQuestion(0)
+	return m, m.answerInput.Focus()
+}
+
+// showQuestion moves to question i, keeping the answer given so far
+func (m *Model) showQuestion(i int) {
+	m.questionIndex = i
+	m.answerInput.SetValue(m.answers[i])
+	m.answerInput.CursorEnd()
+}
+
+// updateQuestions handles the questions view
+func (m Model) updateQuestions(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	if m.generating {
+		if msg.Type == tea.KeyEsc {
+			m.cancelGeneration()
+		}
+		return m, nil
+	}
+	if len(m.sensitive) > 0 {
+		return m.updateSensitive(msg)
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		// Back to the input, which is analyzed again when sent
+		m.state = stateInput
+		m.answerInput.Blur()
+		m.focused = fieldTask
+		m.taskInput.Focus()
+		return m, nil
+
+	case tea.KeyEnter, tea.KeyTab:
+		m.answers[m.questionIndex] = strings.TrimSpace(m.answerInput.Value())
+		if m.questionIndex+1 < len(m.questions) {
+			m.showQuestion(m.questionIndex + 1)
+			return m, nil
+		}
+		return m.finishQuestions()
+
+	case tea.KeyShiftTab:
+		m.answers[m.questionIndex] = strings.TrimSpace(m.answerInput.Value())
+		if m.questionIndex > 0 {
+			m.showQuestion(m.questionIndex - 1)
+		}
+		return m, nil
+
+	case tea.KeyCtrlE:
+		// Generate without answering the remaining questions
+		m.answers[m.questionIndex] = strings.TrimSpace(m.answerInput.Value())
+		return m.finishQuestions()
+	}
+
+	m.answerInput, cmd = m.answerInput.Update(msg)
+	return m, cmd
+}
+
+// finishQuestions generates the prompt, or the candidates, from the answered questions; unanswered ones are left out
+func (m Model) finishQuestions() (tea.Model, tea.Cmd) {
+	if m.overQuota() {
+		m.err = quotaMessage
+		return m, nil
+	}
+	var ok bool
+	if m, ok = m.reviewSensitive(actionAnswers); !ok {
+		return m, nil
+	}
+
+	m.qa = make(map[string]string)
+	for i, q := range m.questions {
+		if m.answers[i] != "" {
+			m.qa[q] = m.redactions.Apply(m.answers[i])
+		}
+	}
+
+	if m.nextAction == actionCandidates {
+		m.answerInput.Blur()
+		return m.startCandidates()
+	}
+
+	m.gen++
+	m.generating = true
+	m.err = ""
+	return m, GeneratePrompt(m.generationContext(), m.enhancer, m.input, m.taskType, m.qa, m.gen)
+}
+
+// handleGenerated shows the generated prompt as the first revision
+func (m Model) handleGenerated(msg generateSuccessMsg) (tea.Model, tea.Cmd) {
+	// The generation is paid for even when the user has moved on
+	m.addUsage(msg.output.Model, msg.output.InputTokens, msg.output.OutputTokens)
+	if msg.gen != m.gen {
+		return m, nil
+	}
+	m.generating = false
+
+	m.state = stateResult
+	m.answerInput.Blur()
+	m.revisions = []ai.Revision{{Prompt: msg.output.EnhancedPrompt, Model: msg.output.Model, PromptVersion: msg.output.PromptVersion}}
+	m.tip = msg.output.Tip
+	m.showRevision(0)
+	m.err = ""
+	m.track(experiment.EventGenerated)
+	return m, nil
+}
+
+// handleGenerateError shows why the analysis or generation failed, leaving the answers to retry with
+func (m Model) handleGenerateError(msg generateErrorMsg) (tea.Model, tea.Cmd) {
+	if msg.gen != m.gen {
+		return m, nil
+	}
+	m.generating = false
+	m.err = msg.err.Error()
+	return m, nil
+}
+
+// generatingStatus describes what the model is working on
+func (m Model) generatingStatus() string {
+	if m.taskType == "" {
+		return "Analyzing your task... [Esc] Cancel"
+	}
+	return "Generating prompt... [Esc] Cancel"
+}
+
+// viewQuestions renders the analysis' questions with the answer being typed
+func (m Model) viewQuestions() string {
+	var b strings.Builder
+
+	// Title
+	b.WriteString(TitleStyle().Render("🐹 PromptGo - A Few Questions"))
+	b.WriteString("\n")
+	b.WriteString(SubtitleStyle().Render(fmt.Sprintf("Task type: %s   Question %d/%d", m.taskType, m.questionIndex+1, len(m.questions))))
+	b.WriteString("\n\n")
+	b.WriteString(m.viewNotice())
+
+	// Questions answered so far
+	for i, q := range m.questions[:m.questionIndex] {
+		answer := m.answers[i]
+		if answer == "" {
+			answer = "(skipped)"
+		}
+		b.WriteString(SubtitleStyle().Render(fmt.Sprintf("%d. %s", i+1, q)))
+		b.WriteString("\n")
+		b.WriteString("   " + truncateRunes(answer, max(m.contentWidth-3, 10)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	// Current question
+	b.WriteString(FieldLabelStyle(true).Render(fmt.Sprintf("%d. %s", m.questionIndex+1, m.questions[m.questionIndex])))
+	b.WriteString("\n")
+	b.WriteString(m.answerInput.View())
+	b.WriteString("\n\n")
+
+	if m.generating {
+		b.WriteString(SubtitleStyle().Render(m.generatingStatus()))
+		b.WriteString("\n\n")
+	}
+
+	// Error message
+	if m.err != "" {
+		b.WriteString(ErrorStyle().Render("❌ " + m.err))
+		b.WriteString("\n\n")
+	}
+
+	// The sensitive data review replaces the usual help
+	if len(m.sensitive) > 0 {
+		b.WriteString(m.viewSensitive())
+		return b.String()
+	}
+
+	// Help
+	b.WriteString(HelpStyle().Render("[Enter] Next   [Shift+Tab] Previous   [Ctrl+E] Generate now   [Esc] Back to task"))
+	b.WriteString("\n")
+
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"promptgo/internal/ai"
+	"promptgo/internal/enhancer"
+)
+
+const refineTimeout = 2 * time.Minute
+
+type refineSuccessMsg struct {
+	gen      int
+	output   *enhancer.Output
+	feedback string
+}
+type refineErrorMsg struct {
+	gen int
+	err error
+}
+
+// RefinePrompt returns a tea.Cmd that asks the model to revise the latest revision.
+// gen is returned with the result so one the user has since abandoned can be dropped.
+func RefinePrompt(ctx context.Context, e *enhancer.Enhancer, input enhancer.Input, taskType ai.TaskType, qa map[string]string, revisions []ai.Revision, feedback string, gen int) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(ctx, refineTimeout)
+		defer cancel()
+
+		output, err := e.Refine(ctx, input, taskType, qa, revisions, feedback)
+		if err != nil {
+			return refineErrorMsg{gen: gen, err: err}
+		}
+
+		return refineSuccessMsg{gen: gen, output: output, feedback: feedback}
+	}
+}
+
+// showRevision displays the revision at index i in the result viewport
+func (m *Model) showRevision(i int) {
+	if i < 0 || i >= len(m.revisions) {
+		return
+	}
+	m.revisionIndex = i
+	m.enhancedPrompt = m.redactions.Restore(m.revisions[i].Prompt)
+	m.setResultContent()
+	m.resultViewport.GotoTop()
+}
@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"promptgo/internal/ai"
+	"promptgo/internal/export"
+)
+
+// downloadReadyMsg reports that a document kept by the server is ready to download with command
+type downloadReadyMsg struct{ command string }
+
+// WithRemoteSave hands saved documents to keep instead of writing files, for sessions where the
+// files would land on the server. The user downloads them with the export or rules command.
+func (m Model) WithRemoteSave(keep func(export.Document)) Model {
+	m.keepDocument = keep
+	return m
+}
+
+// SaveDocument returns a tea.Cmd that writes the document to path in the given format. An existing
+// file is only replaced when overwrite is set.
+func SaveDocument(doc export.Document, e export.Exporter, path string, overwrite bool) tea.Cmd {
+	return func() tea.Msg {
+		data, err := export.Bytes(doc, e)
+		if err != nil {
+			return saveErrorMsg{err: err}
+		}
+		flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+		if overwrite {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		}
+		f, err := os.OpenFile(path, flags, 0644)
+		if err != nil {
+			return saveErrorMsg{err: err}
+		}
+		_, err = f.Write(data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return saveErrorMsg{err: err}
+		}
+		return saveSuccessMsg{path: path}
+	}
+}
+
+// KeepDocument returns a tea.Cmd that hands the document to the server and reports the command
+// that downloads it
+func KeepDocument(keep func(export.Document), doc export.Document, command string) tea.Cmd {
+	return func() tea.Msg {
+		keep(doc)
+		return downloadReadyMsg{command: command}
+	}
+}
+
+// currentDocument collects the shown revision and its metadata for export
+func (m Model) currentDocument() export.Document {
+	taskType := m.taskType
+	if taskType == "" {
+		taskType = ai.TypeOther
+	}
+	doc := export.Document{
+		Prompt:     m.enhancedPrompt,
+		Task:       m.input.Task,
+		Details:    m.input.Details,
+		TaskType:   string(taskType),
+		SecretWord: m.input.SecretWord,
+		CreatedAt:  time.Now(),
+		QA:         m.qa,
+	}
+	if m.revisionIndex < len(m.revisions) {
+		doc.Model = m.revisions[m.revisionIndex].Model
+	}
+	return doc
+}
+
+// openSaveDialog opens the format and filename picker
+func (m Model) openSaveDialog() (tea.Model, tea.Cmd) {
+	e := export.Exporters[m.saveFormat]
+	m.saving = true
+	m.saveConfirm = ""
+	m.err = ""
+	m.saveNameInput.SetValue(export.Filename(m.currentDocument(), e))
+	m.saveNameInput.CursorEnd()
+	return m, m.saveNameInput.Focus()
+}
+
+// updateSaveDialog handles the save dialog
+func (m Model) updateSaveDialog(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	// Anything but a second Enter takes back the offer to overwrite
+	if msg.Type != tea.KeyEnter && m.saveConfirm != "" {
+		m.saveConfirm = ""
+		m.err = ""
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.saving = false
+		m.saveNameInput.Blur()
+		return m, nil
+
+	case tea.KeyTab, tea.KeyShiftTab:
+		// Cycle formats, keeping the filename's extension in step
+		prev := export.Exporters[m.saveFormat]
+		step := 1
+		if msg.Type == tea.KeyShiftTab {
+			step = len(export.Exporters) - 1
+		}
+		m.saveFormat = (m.saveFormat + step) % len(export.Exporters)
+		name := m.saveNameInput.Value()
+		name = strings.TrimSuffix(name, prev.Extension()) + export.Exporters[m.saveFormat].Extension()
+		m.saveNameInput.SetValue(name)
+		m.saveNameInput.CursorEnd()
+		return m, nil
+
+	case tea.KeyEnter:
+		// Saves stay in the working directory whatever path is typed
+		name := filepath.Base(strings.TrimSpace(m.saveNameInput.Value()))
+		if name == "." || name == "/" {
+			m.err = "Filename is required"
+			return m, nil
+		}
+		e := export.Exporters[m.saveFormat]
+
+		// Over SSH the working directory is the server's, so the client downloads the file instead
+		if m.keepDocument != nil {
+			m.saving = false
+			m.saveNameInput.Blur()
+			m.err = ""
+			return m, KeepDocument(m.keepDocument, m.currentDocument(), fmt.Sprintf("ssh … export -format %s > %s", e.Name(), name))
+		}
+
+		path := filepath.Join(".", name)
+		overwrite := m.saveConfirm == path
+		if _, err := os.Stat(path); err == nil && !overwrite {
+			m.saveConfirm = path
+			m.err = name + " already exists; press [Enter] again to overwrite it"
+			return m, nil
+		}
+		m.saving = false
+		m.saveConfirm = ""
+		m.err = ""
+		m.saveNameInput.Blur()
+		return m, SaveDocument(m.currentDocument(), e, path, overwrite)
+	}
+
+	m.saveNameInput, cmd = m.saveNameInput.Update(msg)
+	return m, cmd
+}
+
+// viewSaveDialog renders the format tabs and filename input
+func (m Model) viewSaveDialog() string {
+	var b strings.Builder
+
+	b.WriteString(FieldLabelStyle(true).Render("Format:"))
+	for i, e := range export.Exporters {
+		b.WriteString(" ")
+		b.WriteString(TabStyle(i == m.saveFormat).Render(e.Name()))
+	}
+	b.WriteString("\n")
+	b.WriteString(FieldLabelStyle(true).Render("Filename:"))
+	b.WriteString(" ")
+	b.WriteString(m.saveNameInput.View())
+	b.WriteString("\n")
+
+	return b.String()
+}
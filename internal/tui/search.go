@@ -0,0 +1,273 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	outlineWidth = 28 // Width of the section outline side panel
+	gutterWidth  = 4  // Digits reserved for line numbers
+)
+
+// emphasisReplacer strips inline markdown emphasis so heading titles match rendered text
+var emphasisReplacer = strings.NewReplacer("**", "", "__", "", "`", "")
+
+// searchMatch is the position of a match within a displayed line's plain text
+type searchMatch struct {
+	line       int
+	start, end int
+}
+
+// heading is a markdown heading and the displayed line it appears on
+type heading struct {
+	level int
+	title string
+	line  int
+}
+
+// updateSearch handles the search input, highlighting matches as the query is typed
+func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		// Close the search and clear highlights
+		m.searching = false
+		m.searchInput.Blur()
+		m.searchQuery = ""
+		m.findMatches()
+		m.refreshResultViewport()
+		return m, nil
+
+	case tea.KeyEnter:
+		// Keep the highlights and return to scrolling
+		m.searching = false
+		m.searchInput.Blur()
+		return m, nil
+	}
+
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	if query := m.searchInput.Value(); query != m.searchQuery {
+		m.searchQuery = query
+		m.findMatches()
+		m.jumpToMatch(m.firstMatchFrom(m.resultViewport.YOffset))
+	}
+
+	return m, cmd
+}
+
+// updateOutline handles keys while the section outline has focus
+func (m Model) updateOutline(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.outlineCursor > 0 {
+			m.outlineCursor--
+		}
+		return m, nil
+
+	case "down", "j":
+		if m.outlineCursor < len(m.outline)-1 {
+			m.outlineCursor++
+		}
+		return m, nil
+
+	case "enter":
+		// Jump to the selected section
+		if m.outlineCursor < len(m.outline) {
+			m.resultViewport.SetYOffset(m.outline[m.outlineCursor].line)
+		}
+		return m, nil
+
+	case "o", "esc":
+		m.showOutline = false
+		m.setResultContent()
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// findMatches locates every case-insensitive occurrence of the query in the displayed lines
+func (m *Model) findMatches() {
+	m.matches = nil
+	m.matchIndex = 0
+
+	query := strings.ToLower(m.searchQuery)
+	if query == "" {
+		return
+	}
+
+	for i, line := range m.resultLines {
+		plain := ansi.Strip(line)
+		// Offsets index the plain text, so only fold case when it keeps byte lengths intact
+		if lower := strings.ToLower(plain); len(lower) == len(plain) {
+			plain = lower
+		}
+		offset := 0
+		for {
+			idx := strings.Index(plain[offset:], query)
+			if idx < 0 {
+				break
+			}
+			start := offset + idx
+			m.matches = append(m.matches, searchMatch{line: i, start: start, end: start + len(query)})
+			offset = start + len(query)
+		}
+	}
+}
+
+// firstMatchFrom returns the index of the first match on or after line, wrapping to the top
+func (m Model) firstMatchFrom(line int) int {
+	for i, match := range m.matches {
+		if match.line >= line {
+			return i
+		}
+	}
+	return 0
+}
+
+// jumpToMatch makes match i current and scrolls it into view
+func (m *Model) jumpToMatch(i int) {
+	if len(m.matches) == 0 {
+		m.refreshResultViewport()
+		return
+	}
+
+	m.matchIndex = (i%len(m.matches) + len(m.matches)) % len(m.matches)
+	m.refreshResultViewport()
+
+	line := m.matches[m.matchIndex].line
+	if line < m.resultViewport.YOffset || line >= m.resultViewport.YOffset+m.resultViewport.Height {
+		m.resultViewport.SetYOffset(line - m.resultViewport.Height/2)
+	}
+}
+
+// buildOutline finds the markdown headings in the prompt and the displayed lines they render on
+func (m *Model) buildOutline() {
+	m.outline = nil
+
+	next := 0
+	for _, h := range parseHeadings(m.enhancedPrompt) {
+		for i := next; i < len(m.resultLines); i++ {
+			if strings.Contains(ansi.Strip(m.resultLines[i]), h.title) {
+				h.line = i
+				m.outline = append(m.outline, h)
+				next = i + 1
+				break
+			}
+		}
+	}
+
+	if m.outlineCursor >= len(m.outline) {
+		m.outlineCursor = 0
+	}
+}
+
+// refreshResultViewport applies search highlights and the line-number gutter to the displayed lines
+func (m *Model) refreshResultViewport() {
+	// Group matches by line so each line is highlighted in one pass
+	byLine := make(map[int][]int)
+	for i, match := range m.matches {
+		byLine[match.line] = append(byLine[match.line], i)
+	}
+
+	lines := make([]string, len(m.resultLines))
+	for i, line := range m.resultLines {
+		if idxs, ok := byLine[i]; ok {
+			line = m.highlightLine(line, idxs)
+		}
+		if m.showLineNumbers {
+			line = HelpStyle().Render(fmt.Sprintf("%*d ", gutterWidth, i+1)) + line
+		}
+		lines[i] = line
+	}
+
+	m.resultViewport.SetContent(strings.Join(lines, "\n"))
+}
+
+// highlightLine renders a line as plain text with the given matches highlighted.
+// Styling from the markdown renderer is dropped on matched lines so offsets line up.
+func (m Model) highlightLine(line string, matchIdxs []int) string {
+	plain := ansi.Strip(line)
+
+	var b strings.Builder
+	pos := 0
+	for _, idx := range matchIdxs {
+		match := m.matches[idx]
+		b.WriteString(plain[pos:match.start])
+		style := SearchMatchStyle()
+		if idx == m.matchIndex {
+			style = CurrentMatchStyle()
+		}
+		b.WriteString(style.Render(plain[match.start:match.end]))
+		pos = match.end
+	}
+	b.WriteString(plain[pos:])
+
+	return b.String()
+}
+
+// viewOutline renders the section outline side panel
+func (m Model) viewOutline() string {
+	var b strings.Builder
+	b.WriteString(FieldLabelStyle(true).Render("Sections"))
+	b.WriteString("\n")
+
+	if len(m.outline) == 0 {
+		b.WriteString(HelpStyle().Render("No headings"))
+	}
+	for i, h := range m.outline {
+		title := strings.Repeat("  ", h.level-1) + h.title
+		title = ansi.Truncate(title, outlineWidth-2, "…")
+		b.WriteString(FieldLabelStyle(i == m.outlineCursor).Render(title))
+		b.WriteString("\n")
+	}
+
+	return lipgloss.NewStyle().
+		Width(outlineWidth).
+		Height(m.resultViewport.Height).
+		MarginRight(1).
+		Render(b.String())
+}
+
+// searchStatus describes the current search for the result view
+func (m Model) searchStatus() string {
+	if m.searchQuery == "" {
+		return ""
+	}
+	if len(m.matches) == 0 {
+		return fmt.Sprintf("/%s: no matches", m.searchQuery)
+	}
+	return fmt.Sprintf("/%s: %d/%d", m.searchQuery, m.matchIndex+1, len(m.matches))
+}
+
+// parseHeadings returns the markdown headings in text, skipping fenced code blocks
+func parseHeadings(text string) []heading {
+	var headings []heading
+	inFence := false
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || !strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		level := len(line) - len(strings.TrimLeft(line, "#"))
+		title := strings.TrimSpace(emphasisReplacer.Replace(line[level:]))
+		if title == "" || level > 6 {
+			continue
+		}
+		headings = append(headings, heading{level: level, title: title})
+	}
+
+	return headings
+}
@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"promptgo/internal/redact"
+)
+
+// pendingAction is what to resume once sensitive data has been reviewed
+type pendingAction int
+
+const (
+	actionEnhance pendingAction = iota
+	actionCandidates
+	actionAnswers // Generate from the answered questions
+	actionRefine  // Send the refinement feedback
+)
+
+// WithPrivacyPolicy enforces redaction of credentials; the user can no longer choose to send them
+func (m Model) WithPrivacyPolicy(blockSecrets bool) Model {
+	m.blockSecrets = blockSecrets
+	return m
+}
+
+// sensitiveText is everything action would send to the model: the input fields, the attached
+// project and changes, and the answers or feedback typed since
+func (m Model) sensitiveText(action pendingAction) string {
+	input := m.rawInput()
+	parts := []string{input.Task, input.Details, input.ProjectContext, input.APIMap, input.ChangeContext}
+	switch action {
+	case actionAnswers:
+		parts = append(parts, m.answers...)
+	case actionRefine:
+		parts = append(parts, m.feedbackInput.Value())
+	}
+	return strings.Join(parts, "\n")
+}
+
+// reviewSensitive scans what action would send. It reports false and opens the review when
+// there are findings the user hasn't decided on yet; values already reviewed aren't asked about again.
+func (m Model) reviewSensitive(action pendingAction) (Model, bool) {
+	text := m.sensitiveText(action)
+	if text == m.reviewedText {
+		return m, true
+	}
+
+	var findings []redact.Finding
+	for _, f := range redact.Scan(text) {
+		if !strings.Contains(m.reviewedText, f.Value) {
+			findings = append(findings, f)
+		}
+	}
+	if len(findings) == 0 {
+		m.reviewedText = text
+		return m, true
+	}
+
+	m.sensitive = findings
+	m.sensitiveAction = action
+	m.err = ""
+	return m, false
+}
+
+// forgetRedactions drops the review decisions and placeholder mapping of the current prompt.
+// The mapping is never saved, so a prompt opened from elsewhere keeps its placeholders as they are.
+func (m *Model) forgetRedactions() {
+	m.sensitive = nil
+	m.reviewedText = ""
+	m.redactions = nil
+}
+
+// updateSensitive handles the redact, keep or cancel choice
+func (m Model) updateSensitive(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "r":
+		m.redactions = m.redactions.Add(m.sensitive)
+	case "k":
+		if m.blockSecrets && redact.HasSecrets(m.sensitive) {
+			m.err = "Credentials must be redacted before sending (block on secrets policy)"
+			return m, nil
+		}
+	case "esc":
+		m.sensitive = nil
+		m.err = ""
+		return m, nil
+	default:
+		return m, nil
+	}
+
+	m.sensitive = nil
+	m.reviewedText = m.sensitiveText(m.sensitiveAction)
+	m.err = ""
+
+	switch m.sensitiveAction {
+	case actionCandidates:
+		return m.generateCandidates()
+	case actionAnswers:
+		return m.finishQuestions()
+	case actionRefine:
+		return m.refine()
+	default:
+		return m.enhance()
+	}
+}
+
+// viewSensitive lists the findings awaiting a decision
+func (m Model) viewSensitive() string {
+	var b strings.Builder
+
+	b.WriteString(ErrorStyle().Render(fmt.Sprintf("⚠ Found %d possibly sensitive values in your input:", len(m.sensitive))))
+	b.WriteString("\n")
+	for _, f := range m.sensitive {
+		b.WriteString(fmt.Sprintf("  - %s: %s\n", f.Kind, f.Masked()))
+	}
+	b.WriteString("\n")
+	if m.blockSecrets && redact.HasSecrets(m.sensitive) {
+		b.WriteString(HelpStyle().Render("[r] Redact and send   [Esc] Cancel"))
+	} else {
+		b.WriteString(HelpStyle().Render("[r] Redact and send   [k] Keep and send   [Esc] Cancel"))
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// quotaMessage is shown when a generation is refused because the user's tokens are spent
+const quotaMessage = "You've used your token quota; ask an operator to reset it"
+
+// shutdownMessage is shown while the server drains sessions before stopping
+const shutdownMessage = "The server is restarting, autosaving your draft"
+
+// NoticeMsg shows a server notice in a banner; an empty Text clears it
+type NoticeMsg struct {
+	Text string
+	At   time.Time // Counts down to this time, e.g. the start of maintenance; zero for no countdown
+}
+
+// ShutdownMsg tells the session the server is stopping and will disconnect it at At
+type ShutdownMsg struct {
+	At time.Time
+}
+
+// noticeTickMsg updates the notice countdown
+type noticeTickMsg struct {
+	seq int
+}
+
+// stateNames label each screen for operators
+var stateNames = map[appState]string{
+	stateInput:      "input",
+	stateQuestions:  "questions",
+	stateResult:     "result",
+	stateDiff:       "diff",
+	stateCandidates: "candidates",
+	stateEdit:       "edit",
+	stateChanges:    "changes",
+	stateLibrary:    "library",
+	stateHistory:    "history",
+}
+
+// StateName returns the screen the session is on, adding "generating" while waiting for the model
+func (m Model) StateName() string {
+	name := stateNames[m.state]
+	if m.generating || m.candidateCh != nil {
+		name += " (generating)"
+	}
+	return name
+}
+
+// WithUsage reports the tokens each response used, and stops new generations while exceeded returns true.
+// Either function may be nil.
+func (m Model) WithUsage(record func(model string, inputTokens, outputTokens int64), exceeded func() bool) Model {
+	m.recordUsage = record
+	m.quotaExceeded = exceeded
+	return m
+}
+
+// overQuota reports whether the user has spent their token quota
+func (m Model) overQuota() bool {
+	return m.quotaExceeded != nil && m.quotaExceeded()
+}
+
+// addUsage reports a response's tokens
+func (m Model) addUsage(model string, inputTokens, outputTokens int64) {
+	if m.recordUsage != nil && inputTokens+outputTokens > 0 {
+		m.recordUsage(model, inputTokens, outputTokens)
+	}
+}
+
+// setNotice shows text in the banner, counting down to at when it isn't zero
+func (m Model) setNotice(text string, at time.Time) (tea.Model, tea.Cmd) {
+	m.notice = text
+	m.noticeAt = at
+	m.noticeSeq++
+	if at.IsZero() || text == "" {
+		return m, nil
+	}
+	return m, m.noticeTick()
+}
+
+// noticeTick redraws the countdown every second
+func (m Model) noticeTick() tea.Cmd {
+	seq := m.noticeSeq
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return noticeTickMsg{seq: seq}
+	})
+}
+
+// viewNotice renders the server notice banner, or "" when there is none
+func (m Model) viewNotice() string {
+	if strings.TrimSpace(m.notice) == "" {
+		return ""
+	}
+	text := "📢 " + m.notice
+	if !m.noticeAt.IsZero() {
+		if left := time.Until(m.noticeAt).Round(time.Second); left > 0 {
+			text += fmt.Sprintf(" (in %s)", left)
+		} else {
+			text += " (now)"
+		}
+	}
+	return TipStyle().Render(text) + "\n\n"
+}
@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"promptgo/internal/export"
+	"promptgo/internal/history"
+	"promptgo/internal/share"
+)
+
+// shareExpiries are the lifetimes the share dialog cycles through; zero never expires
+var shareExpiries = []time.Duration{0, time.Hour, 24 * time.Hour, 7 * 24 * time.Hour}
+
+// WithSharing enables sharing prompts as owner; baseURL is where the HTTP pages are served, empty if they aren't
+func (m Model) WithSharing(store *share.Store, owner, baseURL string) Model {
+	m.shares = store
+	m.shareOwner = owner
+	m.shareBaseURL = strings.TrimRight(baseURL, "/")
+	return m
+}
+
+// openShareDialog asks who can see the shared prompt and for how long
+func (m Model) openShareDialog() (tea.Model, tea.Cmd) {
+	if m.shares == nil {
+		m.err = "Sharing is only available when connected over SSH"
+		return m, nil
+	}
+	m.sharing = true
+	m.shareAccess = 0
+	m.shareExpiry = 0
+	m.err = ""
+	return m, nil
+}
+
+// updateShareDialog handles the share dialog
+func (m Model) updateShareDialog(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "tab":
+		m.shareAccess = (m.shareAccess + 1) % len(share.Accesses)
+		// Workspace access needs a workspace to share with
+		if share.Accesses[m.shareAccess] == share.AccessWorkspace && m.currentWorkspace() == nil {
+			m.shareAccess = (m.shareAccess + 1) % len(share.Accesses)
+		}
+
+	case "x":
+		m.shareExpiry = (m.shareExpiry + 1) % len(shareExpiries)
+
+	case "enter":
+		return m.createShare()
+
+	case "esc":
+		m.sharing = false
+	}
+	return m, nil
+}
+
+// createShare saves the prompt on screen under a new share ID
+func (m Model) createShare() (tea.Model, tea.Cmd) {
+	m.sharing = false
+
+	sh := share.Share{
+		Owner:   m.shareOwner,
+		Access:  share.Accesses[m.shareAccess],
+		Title:   export.DefaultTitle(m.input.Task),
+		Prompt:  m.enhancedPrompt,
+		Secret:  m.input.SecretWord,
+		Created: time.Now().UTC(),
+	}
+	if ws := m.currentWorkspace(); ws != nil {
+		sh.Workspace = ws.ID
+	}
+	if d := shareExpiries[m.shareExpiry]; d > 0 {
+		sh.Expires = sh.Created.Add(d)
+	}
+
+	sh, err := m.shares.Create(sh)
+	if err != nil {
+		m.err = "Failed to share: " + err.Error()
+		return m, nil
+	}
+
+	m.tip = fmt.Sprintf("Shared as %s: view it with `ssh … show %s`", sh.ID, sh.ID)
+	if sh.Access == share.AccessLink && m.shareBaseURL != "" {
+		m.tip += " or at " + m.shareBaseURL + "/p/" + sh.ID
+	}
+	m.tip += fmt.Sprintf(". Revoke with `ssh … revoke %s`", sh.ID)
+
+	// Remember the share with the history entry so it can be revoked from there
+	if m.history != nil && m.historyID != "" {
+		err := m.history.Update(m.historyUser, m.historyID, func(e *history.Entry) {
+			e.Shares = append(e.Shares, sh.ID)
+		})
+		if err != nil {
+			m.err = "Failed to save the share to history: " + err.Error()
+		} else {
+			m.tip += " or with [x] in your history"
+		}
+	}
+	m.tip += "."
+	return m, nil
+}
+
+// expiryLabel describes a share lifetime
+func expiryLabel(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "never"
+	case d < 24*time.Hour:
+		return fmt.Sprintf("in %d hour(s)", int(d.Hours()))
+	default:
+		return fmt.Sprintf("in %d day(s)", int(d.Hours()/24))
+	}
+}
+
+// viewShareDialog renders the access and expiry choices
+func (m Model) viewShareDialog() string {
+	var b strings.Builder
+
+	access := share.Accesses[m.shareAccess]
+	var who string
+	switch access {
+	case share.AccessLink:
+		who = "anyone with the link"
+	case share.AccessWorkspace:
+		who = "members of " + m.currentWorkspace().Name
+	case share.AccessOwner:
+		who = "only you"
+	}
+
+	b.WriteString(FieldLabelStyle(true).Render("Share prompt:"))
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf("  Visible to: %s\n", who))
+	b.WriteString(fmt.Sprintf("  Expires:    %s\n", expiryLabel(shareExpiries[m.shareExpiry])))
+
+	return b.String()
+}
@@ -92,3 +92,60 @@ func CursorStyle() lipgloss.Style {
 	return lipgloss.NewStyle().
 		Foreground(primaryColor)
 }
+
+// DiffInsertStyle returns the style for inserted diff lines
+func DiffInsertStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Foreground(successColor)
+}
+
+// DiffDeleteStyle returns the style for deleted diff lines
+func DiffDeleteStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Foreground(errorColor)
+}
+
+// DiffInsertWordStyle returns the style for inserted words within a changed line
+func DiffInsertWordStyle() lipgloss.Style {
+	return DiffInsertStyle().
+		Bold(true).
+		Underline(true)
+}
+
+// DiffDeleteWordStyle returns the style for deleted words within a changed line
+func DiffDeleteWordStyle() lipgloss.Style {
+	return DiffDeleteStyle().
+		Bold(true).
+		Strikethrough(true)
+}
+
+// TabStyle returns the style for a tab, highlighted when active
+func TabStyle(active bool) lipgloss.Style {
+	style := lipgloss.NewStyle().
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder(), true, true, false, true)
+	if active {
+		return style.
+			Foreground(primaryColor).
+			BorderForeground(primaryColor).
+			Bold(true)
+	}
+	return style.
+		Foreground(secondaryColor).
+		BorderForeground(secondaryColor)
+}
+
+// SearchMatchStyle returns the style for search matches
+func SearchMatchStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("0")).
+		Background(secondaryColor)
+}
+
+// CurrentMatchStyle returns the style for the selected search match
+func CurrentMatchStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("0")).
+		Background(accentColor).
+		Bold(true)
+}
@@ -0,0 +1,200 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"promptgo/internal/ai"
+	"promptgo/internal/enhancer"
+	"promptgo/internal/export"
+	"promptgo/internal/workspace"
+)
+
+// Workspace is a team workspace the session can switch to
+type Workspace struct {
+	workspace.Workspace
+	Enhancer *enhancer.Enhancer // Uses the workspace's model and budget; nil uses the session's
+}
+
+// libraryItem is a row in the library view: a template or a published prompt
+type libraryItem struct {
+	template  *workspace.Template
+	published *workspace.Published
+}
+
+// WithWorkspaces lets the session switch between workspaces, starting in the first.
+// Prompts are published to library as author.
+func (m Model) WithWorkspaces(workspaces []Workspace, library *workspace.Library, author string) Model {
+	m.workspaces = workspaces
+	m.library = library
+	m.author = author
+	m.defaultEnhancer = m.enhancer
+	m.workspaceIndex = -1
+	if len(workspaces) > 0 {
+		m.selectWorkspace(0)
+	}
+	return m
+}
+
+// currentWorkspace returns the active workspace, or nil when working alone
+func (m Model) currentWorkspace() *Workspace {
+	if m.workspaceIndex < 0 || m.workspaceIndex >= len(m.workspaces) {
+		return nil
+	}
+	return &m.workspaces[m.workspaceIndex]
+}
+
+// selectWorkspace switches to workspace i, or to none when i is out of range
+func (m *Model) selectWorkspace(i int) {
+	m.workspaceIndex = i
+	m.enhancer = m.defaultEnhancer
+	if ws := m.currentWorkspace(); ws == nil {
+		m.workspaceIndex = -1
+	} else if ws.Enhancer != nil {
+		m.enhancer = ws.Enhancer
+	}
+}
+
+// nextWorkspace cycles through the user's workspaces and then working alone
+func (m Model) nextWorkspace() (tea.Model, tea.Cmd) {
+	if len(m.workspaces) == 0 {
+		m.err = "You aren't a member of any workspace"
+		return m, nil
+	}
+	m.selectWorkspace(m.workspaceIndex + 1)
+	m.err = ""
+	return m, nil
+}
+
+// openLibrary shows the active workspace's templates and published prompts
+func (m Model) openLibrary() (tea.Model, tea.Cmd) {
+	ws := m.currentWorkspace()
+	if ws == nil {
+		m.err = "Switch to a workspace with [Ctrl+W] to see its library"
+		return m, nil
+	}
+
+	m.libraryItems = nil
+	for i := range ws.Templates {
+		m.libraryItems = append(m.libraryItems, libraryItem{template: &ws.Templates[i]})
+	}
+	if m.library != nil {
+		for _, p := range m.library.List(ws.ID) {
+			m.libraryItems = append(m.libraryItems, libraryItem{published: &p})
+		}
+	}
+	if len(m.libraryItems) == 0 {
+		m.err = ws.Name + " has no templates or published prompts yet"
+		return m, nil
+	}
+
+	m.blurAll()
+	m.libraryCursor = 0
+	m.state = stateLibrary
+	m.err = ""
+	return m, nil
+}
+
+// updateLibrary handles the library view
+func (m Model) updateLibrary(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.libraryCursor > 0 {
+			m.libraryCursor--
+		}
+
+	case "down", "j":
+		if m.libraryCursor < len(m.libraryItems)-1 {
+			m.libraryCursor++
+		}
+
+	case "enter":
+		item := m.libraryItems[m.libraryCursor]
+		if item.template != nil {
+			// Prefill the input fields from the template
+			m.taskInput.SetValue(item.template.Task)
+			m.detailsInput.SetValue(item.template.Details)
+			return m.closeLibrary()
+		}
+		// Open the published prompt as the only revision
+		p := item.published
+		m.forgetRedactions()
+		m.input = m.currentInput()
+		m.input.SecretWord = p.SecretWord
+		// Published prompts don't keep the analysis they were generated from
+		m.taskType, m.qa = ai.TypeOther, nil
+		m.revisions = []ai.Revision{{Prompt: p.Prompt, PromptVersion: p.PromptVersion}}
+		m.tip = fmt.Sprintf("Published by %s on %s. Use [f] to adapt it to your task.", p.Author, p.Time.Format("2006-01-02"))
+		m.state = stateResult
+		m.showRevision(0)
+
+	case "esc":
+		return m.closeLibrary()
+	}
+	return m, nil
+}
+
+// closeLibrary returns to the input view
+func (m Model) closeLibrary() (tea.Model, tea.Cmd) {
+	m.state = stateInput
+	m.focused = fieldTask
+	return m, m.taskInput.Focus()
+}
+
+// publish adds the prompt on screen to the active workspace's library
+func (m Model) publish() (tea.Model, tea.Cmd) {
+	ws := m.currentWorkspace()
+	if ws == nil || m.library == nil {
+		m.err = "Switch to a workspace with [Ctrl+W] before publishing"
+		return m, nil
+	}
+
+	p := workspace.Published{
+		Workspace:  ws.ID,
+		Title:      export.DefaultTitle(m.input.Task),
+		Prompt:     m.enhancedPrompt,
+		SecretWord: m.input.SecretWord,
+		Author:     m.author,
+		Time:       time.Now().UTC(),
+	}
+	if m.revisionIndex < len(m.revisions) {
+		p.PromptVersion = m.revisions[m.revisionIndex].PromptVersion
+	}
+	if err := m.library.Publish(p); err != nil {
+		m.err = "Failed to publish: " + err.Error()
+		return m, nil
+	}
+
+	m.saveFeedback = "✓ Published to " + ws.Name
+	return m, tea.Tick(3*time.Second, func(t time.Time) tea.Msg {
+		return hideSaveFeedbackMsg{}
+	})
+}
+
+// viewLibrary renders the workspace library
+func (m Model) viewLibrary() string {
+	var b strings.Builder
+
+	b.WriteString(TitleStyle().Render("🐹 PromptGo - " + m.currentWorkspace().Name + " Library"))
+	b.WriteString("\n\n")
+
+	for i, item := range m.libraryItems {
+		var line string
+		if item.template != nil {
+			line = "📋 " + item.template.Name
+		} else {
+			p := item.published
+			line = fmt.Sprintf("📄 %s · %s · %s", p.Title, p.Author, p.Time.Format("2006-01-02"))
+		}
+		b.WriteString(FieldLabelStyle(i == m.libraryCursor).Render(line))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(HelpStyle().Render("[↑/↓] Select   [Enter] Use template / open prompt   [Esc] Back"))
+	b.WriteString("\n")
+
+	return b.String()
+}
@@ -0,0 +1,135 @@
+package workspace
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Everyone is a member entry that admits every user
+const Everyone = "*"
+
+// Template prefills the task and details for a kind of work the team does often
+type Template struct {
+	Name    string
+	Task    string
+	Details string
+}
+
+// Workspace is a team's shared conventions and settings
+type Workspace struct {
+	ID      string
+	Name    string
+	Members []string // SSH key fingerprints ("SHA256:...") or Everyone
+
+	HouseRules string // Appended to every generated prompt
+	Templates  []Template
+
+	// Empty or zero settings use the server's defaults
+	Model       string
+	MaxTokens   int64
+	InputBudget int
+}
+
+// HasMember reports whether user belongs to the workspace
+func (w Workspace) HasMember(user string) bool {
+	for _, m := range w.Members {
+		if m == Everyone || m == user {
+			return true
+		}
+	}
+	return false
+}
+
+// Published is a prompt shared with a workspace's library
+type Published struct {
+	Workspace     string    `json:"workspace"`
+	Title         string    `json:"title"`
+	Prompt        string    `json:"prompt"`
+	SecretWord    string    `json:"secret_word"` // The gate word in Prompt, so readers can check the gate
+	Author        string    `json:"author"`
+	PromptVersion string    `json:"prompt_version,omitempty"`
+	Time          time.Time `json:"time"`
+}
+
+// Library holds the prompts published to every workspace, appended to a JSONL file
+type Library struct {
+	mu    sync.RWMutex
+	path  string // Empty keeps the library in memory only
+	items []Published
+}
+
+// NewLibrary opens the library at path, loading the prompts already published
+func NewLibrary(path string) (*Library, error) {
+	l := &Library{path: path}
+	if path == "" {
+		return l, nil
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open prompt library: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 4<<20) // Prompts are long
+	for line := 1; scanner.Scan(); line++ {
+		var p Published
+		if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		l.items = append(l.items, p)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read prompt library: %w", err)
+	}
+	return l, nil
+}
+
+// Publish adds a prompt to its workspace's library
+func (l *Library) Publish(p Published) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.items = append(l.items, p)
+	if l.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// List returns a workspace's published prompts, newest first
+func (l *Library) List(workspaceID string) []Published {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var out []Published
+	for i := len(l.items) - 1; i >= 0; i-- {
+		if l.items[i].Workspace == workspaceID {
+			out = append(out, l.items[i])
+		}
+	}
+	return out
+}